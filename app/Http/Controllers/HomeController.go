@@ -3,33 +3,72 @@ package controllers
 import (
 	"encoding/json"
 	models "enzovu/app/Models"
+	"enzovu/enzovu"
 	"enzovu/helpers"
 	"fmt"
 	"net/http"
+	"os"
 	"path/filepath"
+	"time"
 )
 
 // Home handles the request to the homepage and serves the index.html file
 func Home(w http.ResponseWriter, r *http.Request) {
 	// Serve the index.html file from the public directory
-	http.ServeFile(w, r, filepath.Join("public", "index.html"))
-}
-
-func About(w http.ResponseWriter, r *http.Request) {
-	// print a message
-	fmt.Fprintf(w, "Welcome to Go-Laravel-like Framework")
+	indexPath := filepath.Join("public", "index.html")
+	if _, err := os.Stat(indexPath); err == nil {
+		http.ServeFile(w, r, indexPath)
+		return
+	}
 
+	// Fallback to simple welcome message
+	w.Header().Set("Content-Type", "text/html")
+	fmt.Fprint(w, welcomePage)
 }
 
-func TestModel(w http.ResponseWriter, r *http.Request) {
-	user := models.GetUser()
+// About describes the framework, as the about view for browsers and as
+// JSON, XML or text for other clients
+func About(c *enzovu.Context) error {
+	return c.NegotiateView("about", map[string]interface{}{
+		"message":   "Welcome to Enzovu Framework",
+		"framework": "Enzovu",
+		"version":   "1.0.0",
+		"language":  "Go",
+		"inspired":  "Laravel",
+		"features": []string{
+			"MVC Architecture",
+			"CLI Code Generation",
+			"Middleware Support",
+			"Elegant Routing",
+			"Database Integration",
+		},
+	})
+}
 
-	// Set the response header to application/json
-	w.Header().Set("Content-Type", "application/json")
+// Health provides a health check endpoint
+func Health(c *enzovu.Context) error {
+	return c.Negotiate(map[string]interface{}{
+		"status":    "ok",
+		"framework": "enzovu",
+		"timestamp": time.Now().Format(time.RFC3339),
+		"uptime":    "running",
+	})
+}
 
-	// Write the user data to the response
-	json.NewEncoder(w).Encode(user)
+// APITest provides a simple test endpoint for GET and POST
+func APITest(c *enzovu.Context) error {
+	message := "Hello from Enzovu!"
+	if c.Request.Method == http.MethodPost {
+		message = "POST request received!"
+	}
+	return c.Negotiate(map[string]string{
+		"message": message,
+		"method":  c.Request.Method,
+	})
+}
 
+func TestModel(c *enzovu.Context) error {
+	return c.JSON(models.GetUser())
 }
 
 type UserController struct{}
@@ -62,3 +101,52 @@ func (u *UserController) Show(w http.ResponseWriter, r *http.Request) {
 	// Render the 'show' view with the user data
 
 }
+
+// welcomePage is shown when public/index.html is missing
+const welcomePage = `
+<!DOCTYPE html>
+<html>
+<head>
+    <title>Enzovu Framework</title>
+    <style>
+        body { 
+            font-family: Arial, sans-serif; 
+            text-align: center; 
+            padding: 50px;
+            background: linear-gradient(135deg, #667eea 0%, #764ba2 100%);
+            color: white;
+            margin: 0;
+            min-height: 100vh;
+            display: flex;
+            align-items: center;
+            justify-content: center;
+            flex-direction: column;
+        }
+        h1 { font-size: 3em; margin-bottom: 20px; }
+        p { font-size: 1.2em; margin-bottom: 30px; }
+        .links { margin-top: 30px; }
+        .links a { 
+            color: #ffeb3b; 
+            text-decoration: none; 
+            margin: 0 15px;
+            padding: 10px 20px;
+            border: 2px solid #ffeb3b;
+            border-radius: 5px;
+            transition: all 0.3s;
+        }
+        .links a:hover { 
+            background: #ffeb3b; 
+            color: #333; 
+        }
+    </style>
+</head>
+<body>
+    <h1>🐘 Welcome to Enzovu!</h1>
+    <p>Your elegant Go framework is running successfully!</p>
+    <div class="links">
+        <a href="/about">About</a>
+        <a href="/api/health">Health Check</a>
+        <a href="/test-model">Test Model</a>
+    </div>
+</body>
+</html>`
@@ -39,6 +39,24 @@ func (lrw *loggingResponseWriter) WriteHeader(code int) {
 	lrw.ResponseWriter.WriteHeader(code)
 }
 
+// Flush passes flushes through, so streaming responses such as SSE work
+// behind the logger
+func (lrw *loggingResponseWriter) Flush() {
+	lrw.FlushError()
+}
+
+// FlushError is Flush for http.ResponseController, reporting writers
+// that cannot flush
+func (lrw *loggingResponseWriter) FlushError() error {
+	return http.NewResponseController(lrw.ResponseWriter).Flush()
+}
+
+// Unwrap lets http.ResponseController reach the connection's writer, for
+// deadlines and hijacking
+func (lrw *loggingResponseWriter) Unwrap() http.ResponseWriter {
+	return lrw.ResponseWriter
+}
+
 func logRequest(r *http.Request, statusCode int, duration time.Duration) {
 	cfg := config.GetConfig()
 
@@ -67,10 +67,10 @@ type %[1]s struct {
 	ID    int    `+"`json:\"id\" db:\"id\"`"+`
 
 	// Name is the name of the resource
-	Name  string `+"`json:\"name\" db:\"name\"`"+`
+	Name  string `+"`json:\"name\" db:\"name\" validate:\"required,max=255\"`"+`
 
 	// Email is the email of the resource owner (optional)
-	Email string `+"`json:\"email\" db:\"email\"`"+`
+	Email string `+"`json:\"email\" db:\"email\" validate:\"required,email\"`"+`
 
 	// Timestamps
 	CreatedAt time.Time `+"`json:\"created_at\" db:\"created_at\"`"+`
@@ -153,115 +153,96 @@ func createController(name string) {
 	controllerContent := fmt.Sprintf(`package controllers
 
 import (
-	"encoding/json"
 	"net/http"
-	"strconv"
-	
+
 	"enzovu/app/Models"
-	"enzovu/routes"
+	"enzovu/enzovu"
 )
 
 // %[1]sController handles HTTP requests for %[1]s resources
 type %[1]sController struct{}
 
 // Index handles GET /%[2]s - List all %[2]s
-func (c *%[1]sController) Index(w http.ResponseWriter, r *http.Request) {
-	%[2]s := models.GetAll%[1]s()
-	
-	w.Header().Set("Content-Type", "application/json")
-	json.NewEncoder(w).Encode(%[2]s)
+func (c *%[1]sController) Index(ctx *enzovu.Context) error {
+	return ctx.JSON(models.GetAll%[1]s())
 }
 
 // Show handles GET /%[2]s/{id} - Show a specific %[1]s
-func (c *%[1]sController) Show(w http.ResponseWriter, r *http.Request) {
-	// Get ID parameter from URL
-	idStr := routes.GetParam(r, "id")
-	id, err := strconv.Atoi(idStr)
+func (c *%[1]sController) Show(ctx *enzovu.Context) error {
+	id, err := ctx.ParamInt("id")
 	if err != nil {
-		http.Error(w, "Invalid ID", http.StatusBadRequest)
-		return
+		return err
 	}
-	
+
 	// TODO: Fetch %[1]s by ID from database
 	%[3]s := models.Get%[1]s()
 	%[3]s.ID = id
-	
-	w.Header().Set("Content-Type", "application/json")
-	json.NewEncoder(w).Encode(%[3]s)
+
+	return ctx.JSON(%[3]s)
 }
 
 // Create handles POST /%[2]s - Create a new %[1]s
-func (c *%[1]sController) Create(w http.ResponseWriter, r *http.Request) {
+func (c *%[1]sController) Create(ctx *enzovu.Context) error {
 	var %[3]s models.%[1]s
-	
-	if err := json.NewDecoder(r.Body).Decode(&%[3]s); err != nil {
-		http.Error(w, "Invalid JSON", http.StatusBadRequest)
-		return
+
+	// Bind the JSON or form body and check the model's validate tags:
+	// malformed input is answered with 400, failed rules with 422
+	if err := ctx.Bind(&%[3]s); err != nil {
+		return err
 	}
-	
-	// TODO: Validate and save to database
+
+	// TODO: Save to database
 	if err := %[3]s.Save(); err != nil {
-		http.Error(w, "Failed to create %[3]s", http.StatusInternalServerError)
-		return
+		return err
 	}
-	
-	w.Header().Set("Content-Type", "application/json")
-	w.WriteHeader(http.StatusCreated)
-	json.NewEncoder(w).Encode(%[3]s)
+
+	return ctx.Status(http.StatusCreated).JSON(%[3]s)
 }
 
 // Update handles PUT /%[2]s/{id} - Update a %[1]s
-func (c *%[1]sController) Update(w http.ResponseWriter, r *http.Request) {
-	idStr := routes.GetParam(r, "id")
-	id, err := strconv.Atoi(idStr)
+func (c *%[1]sController) Update(ctx *enzovu.Context) error {
+	id, err := ctx.ParamInt("id")
 	if err != nil {
-		http.Error(w, "Invalid ID", http.StatusBadRequest)
-		return
+		return err
 	}
-	
+
 	var %[3]s models.%[1]s
-	if err := json.NewDecoder(r.Body).Decode(&%[3]s); err != nil {
-		http.Error(w, "Invalid JSON", http.StatusBadRequest)
-		return
+	if err := ctx.Bind(&%[3]s); err != nil {
+		return err
 	}
-	
 	%[3]s.ID = id
-	
+
 	// TODO: Update in database
 	if err := %[3]s.Save(); err != nil {
-		http.Error(w, "Failed to update %[3]s", http.StatusInternalServerError)
-		return
+		return err
 	}
-	
-	w.Header().Set("Content-Type", "application/json")
-	json.NewEncoder(w).Encode(%[3]s)
+
+	return ctx.JSON(%[3]s)
 }
 
 // Delete handles DELETE /%[2]s/{id} - Delete a %[1]s
-func (c *%[1]sController) Delete(w http.ResponseWriter, r *http.Request) {
-	idStr := routes.GetParam(r, "id")
-	id, err := strconv.Atoi(idStr)
+func (c *%[1]sController) Delete(ctx *enzovu.Context) error {
+	id, err := ctx.ParamInt("id")
 	if err != nil {
-		http.Error(w, "Invalid ID", http.StatusBadRequest)
-		return
+		return err
 	}
-	
+
 	// TODO: Fetch and delete from database
 	%[3]s := models.Get%[1]s()
 	%[3]s.ID = id
-	
+
 	if err := %[3]s.Delete(); err != nil {
-		http.Error(w, "Failed to delete %[3]s", http.StatusInternalServerError)
-		return
+		return err
 	}
-	
-	w.WriteHeader(http.StatusNoContent)
+
+	return ctx.NoContent()
 }
 
-// Simple function-based handler for backward compatibility
-func %[1]s(w http.ResponseWriter, r *http.Request) {
+// %[1]sIndex is Index as a plain function, for a single route:
+// router.GETCtx("/%[2]s", controllers.%[1]sIndex)
+func %[1]sIndex(ctx *enzovu.Context) error {
 	controller := &%[1]sController{}
-	controller.Index(w, r)
+	return controller.Index(ctx)
 }
 `, name, strings.ToLower(name)+"s", strings.ToLower(name))
 
@@ -272,7 +253,7 @@ func %[1]s(w http.ResponseWriter, r *http.Request) {
 	}
 
 	fmt.Printf("✅ Controller %s created successfully at %s\n", name, controllerPath)
-	fmt.Printf("💡 Don't forget to register your routes in routes/web.go\n")
+	fmt.Printf("💡 Register its routes in routes/web.go: router.Resource(\"/%s\", &controllers.%sController{})\n", strings.ToLower(name)+"s", name)
 }
 
 // Function to create a new middleware file
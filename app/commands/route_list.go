@@ -0,0 +1,111 @@
+// app/commands/route_list.go
+package commands
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"strings"
+	"text/tabwriter"
+
+	"enzovu/routes"
+
+	"github.com/spf13/cobra"
+)
+
+var (
+	routeListMethod string
+	routeListPath   string
+	routeListName   string
+	routeListJSON   bool
+)
+
+// RouteListCmd prints the application's routing table
+var RouteListCmd = &cobra.Command{
+	Use:   "route:list",
+	Short: "List all registered routes",
+	Long:  `List the routes registered in routes/web.go with their method, pattern, name, handler and middleware chain.`,
+	Args:  cobra.NoArgs,
+	Run: func(cmd *cobra.Command, args []string) {
+		handler, err := routes.SetupRoutes()
+		if err != nil {
+			fmt.Println("❌", err)
+			os.Exit(1)
+		}
+
+		router, ok := handler.(*routes.Router)
+		if !ok {
+			fmt.Println("❌ routes.SetupRoutes does not return a *routes.Router, so its routes cannot be listed")
+			os.Exit(1)
+		}
+
+		list := filterRoutes(router.Routes())
+
+		if routeListJSON {
+			encoder := json.NewEncoder(os.Stdout)
+			encoder.SetIndent("", "  ")
+			if err := encoder.Encode(list); err != nil {
+				fmt.Println("❌ Error encoding routes:", err)
+				os.Exit(1)
+			}
+			return
+		}
+
+		printRouteTable(list)
+	},
+}
+
+func init() {
+	RouteListCmd.Flags().StringVarP(&routeListMethod, "method", "m", "", "only show routes for this HTTP method")
+	RouteListCmd.Flags().StringVarP(&routeListPath, "path", "p", "", "only show routes whose pattern starts with this prefix")
+	RouteListCmd.Flags().StringVarP(&routeListName, "name", "n", "", "only show routes whose name contains this text")
+	RouteListCmd.Flags().BoolVar(&routeListJSON, "json", false, "output the routes as JSON")
+}
+
+func filterRoutes(all []routes.RouteInfo) []routes.RouteInfo {
+	list := make([]routes.RouteInfo, 0, len(all))
+	for _, route := range all {
+		if routeListMethod != "" && !strings.EqualFold(route.Method, routeListMethod) {
+			continue
+		}
+		if routeListPath != "" && !strings.HasPrefix(route.Pattern, routeListPath) {
+			continue
+		}
+		if routeListName != "" && !strings.Contains(route.Name, routeListName) {
+			continue
+		}
+		list = append(list, route)
+	}
+	return list
+}
+
+func printRouteTable(list []routes.RouteInfo) {
+	if len(list) == 0 {
+		fmt.Println("ℹ️  No routes match")
+		return
+	}
+
+	showHost := false
+	for _, route := range list {
+		showHost = showHost || route.Host != ""
+	}
+
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	if showHost {
+		fmt.Fprintln(w, "METHOD\tHOST\tPATTERN\tNAME\tHANDLER\tMIDDLEWARE")
+	} else {
+		fmt.Fprintln(w, "METHOD\tPATTERN\tNAME\tHANDLER\tMIDDLEWARE")
+	}
+
+	for _, route := range list {
+		middleware := strings.Join(route.Middlewares, " → ")
+		if showHost {
+			fmt.Fprintf(w, "%s\t%s\t%s\t%s\t%s\t%s\n", route.Method, route.Host, route.Pattern, route.Name, route.Handler, middleware)
+		} else {
+			fmt.Fprintf(w, "%s\t%s\t%s\t%s\t%s\n", route.Method, route.Pattern, route.Name, route.Handler, middleware)
+		}
+	}
+	w.Flush()
+
+	fmt.Printf("\nShowing %d routes\n", len(list))
+}
@@ -0,0 +1,60 @@
+// app/commands/serve.go
+package commands
+
+import (
+	"fmt"
+	"os"
+
+	"enzovu/config"
+	"enzovu/server"
+
+	"github.com/spf13/cobra"
+)
+
+// serveFlags maps each serve flag to the environment variable it
+// overrides, so the development server's rebuilt children see it too
+var serveFlags = map[string]string{
+	"host":          "APP_HOST",
+	"port":          "APP_PORT",
+	"env":           "APP_ENV",
+	"tls-cert":      "SERVER_TLS_CERT",
+	"tls-key":       "SERVER_TLS_KEY",
+	"watch":         "WATCH_ENABLED",
+	"read-timeout":  "SERVER_READ_TIMEOUT",
+	"write-timeout": "SERVER_WRITE_TIMEOUT",
+	"idle-timeout":  "SERVER_IDLE_TIMEOUT",
+}
+
+// ServeCmd starts the application server
+var ServeCmd = &cobra.Command{
+	Use:   "serve",
+	Short: "Start the application server",
+	Long: `Start the application server with the configuration from the environment and .env, overridden by flags.
+In development the app is rebuilt and reloaded on changes, unless --watch=false.`,
+	Args: cobra.NoArgs,
+	Run: func(cmd *cobra.Command, args []string) {
+		for name, key := range serveFlags {
+			if flag := cmd.Flags().Lookup(name); flag.Changed {
+				os.Setenv(key, flag.Value.String())
+			}
+		}
+
+		if err := server.Run(config.LoadConfig()); err != nil {
+			fmt.Println("❌", err)
+			os.Exit(1)
+		}
+	},
+}
+
+func init() {
+	// Defaults come from the configuration; flags only override it
+	ServeCmd.Flags().String("host", "", "interface to listen on, all by default (APP_HOST)")
+	ServeCmd.Flags().StringP("port", "p", "", "port to listen on, 8000 by default (APP_PORT)")
+	ServeCmd.Flags().StringP("env", "e", "", "environment, development or production (APP_ENV)")
+	ServeCmd.Flags().String("tls-cert", "", "TLS certificate file, serves HTTPS with --tls-key (SERVER_TLS_CERT)")
+	ServeCmd.Flags().String("tls-key", "", "TLS private key file (SERVER_TLS_KEY)")
+	ServeCmd.Flags().Bool("watch", true, "rebuild and reload on changes in development (WATCH_ENABLED)")
+	ServeCmd.Flags().Duration("read-timeout", 0, "limit for reading a request, 15s by default (SERVER_READ_TIMEOUT)")
+	ServeCmd.Flags().Duration("write-timeout", 0, "limit for writing a response, 15s by default (SERVER_WRITE_TIMEOUT)")
+	ServeCmd.Flags().Duration("idle-timeout", 0, "keep-alive timeout between requests, 60s by default (SERVER_IDLE_TIMEOUT)")
+}
@@ -0,0 +1,144 @@
+// Package binding fills structs from HTTP requests using struct tags:
+//
+//	type CreatePost struct {
+//		Title  string                `json:"title" form:"title"`
+//		Tags   []string              `json:"tags" form:"tags"`
+//		Draft  bool                  `query:"draft"`
+//		UserID int                   `param:"user"`
+//		Cover  *multipart.FileHeader `form:"cover"`
+//	}
+//
+// Bind reads the query string (`query`), then the body according to its
+// Content-Type: JSON by `json` tags, urlencoded and multipart forms by
+// `form` tags, then route parameters (`param`). Later sources overwrite
+// earlier ones, so a body cannot override the route's parameters.
+package binding
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"mime"
+	"net/http"
+	"net/url"
+	"strings"
+)
+
+// DefaultMaxMemory is the part of a multipart body kept in memory, the
+// rest of its files are stored on disk
+const DefaultMaxMemory = 32 << 20
+
+// Error reports request input that could not be bound
+type Error struct {
+	Source string // "param", "query", "json", "form" or "body"
+	Field  string // tag name of the field, "" for the body as a whole
+	Status int    // 400, or 415 for an unsupported content type
+	Err    error
+}
+
+func (e *Error) Error() string {
+	if e.Field == "" {
+		return fmt.Sprintf("binding %s: %v", e.Source, e.Err)
+	}
+	return fmt.Sprintf("binding %s field %q: %v", e.Source, e.Field, e.Err)
+}
+
+func (e *Error) Unwrap() error {
+	return e.Err
+}
+
+// StatusCode returns the HTTP status the error is answered with
+func (e *Error) StatusCode() int {
+	if e.Status == 0 {
+		return http.StatusBadRequest
+	}
+	return e.Status
+}
+
+// Bind fills dst, a pointer to a struct, from the query string, the
+// request body and route params, the params taking precedence
+func Bind(r *http.Request, dst interface{}, params map[string]string) error {
+	if err := Query(r, dst); err != nil {
+		return err
+	}
+	if err := Body(r, dst); err != nil {
+		return err
+	}
+	return Params(params, dst)
+}
+
+// Body fills dst from the request body according to its Content-Type.
+// Requests without a Content-Type are left alone.
+func Body(r *http.Request, dst interface{}) error {
+	contentType := r.Header.Get("Content-Type")
+	if contentType == "" || r.Body == nil || r.Body == http.NoBody {
+		return nil
+	}
+
+	mediaType, _, err := mime.ParseMediaType(contentType)
+	if err != nil {
+		return &Error{Source: "body", Err: err}
+	}
+	switch {
+	case mediaType == "application/json" || strings.HasSuffix(mediaType, "+json"):
+		return JSON(r, dst)
+	case mediaType == "application/x-www-form-urlencoded":
+		return Form(r, dst)
+	case mediaType == "multipart/form-data":
+		return Multipart(r, dst, DefaultMaxMemory)
+	}
+	return &Error{
+		Source: "body",
+		Status: http.StatusUnsupportedMediaType,
+		Err:    fmt.Errorf("unsupported content type %q", mediaType),
+	}
+}
+
+// JSON decodes a JSON body into dst. An empty body leaves dst unchanged.
+func JSON(r *http.Request, dst interface{}) error {
+	err := json.NewDecoder(r.Body).Decode(dst)
+	if err == nil || errors.Is(err, io.EOF) {
+		return nil
+	}
+
+	var typeErr *json.UnmarshalTypeError
+	if errors.As(err, &typeErr) {
+		return &Error{Source: "json", Field: typeErr.Field, Err: fmt.Errorf("expected %s, got %s", typeErr.Type, typeErr.Value)}
+	}
+	return &Error{Source: "json", Err: err}
+}
+
+// Form fills the `form` fields of dst from a urlencoded body
+func Form(r *http.Request, dst interface{}) error {
+	if err := r.ParseForm(); err != nil {
+		return &Error{Source: "form", Err: err}
+	}
+	return setFields(dst, "form", r.PostForm)
+}
+
+// Multipart fills the `form` fields of dst from a multipart body,
+// including *multipart.FileHeader and []*multipart.FileHeader fields
+func Multipart(r *http.Request, dst interface{}, maxMemory int64) error {
+	if err := r.ParseMultipartForm(maxMemory); err != nil {
+		return &Error{Source: "form", Err: err}
+	}
+	if err := setFields(dst, "form", r.MultipartForm.Value); err != nil {
+		return err
+	}
+	return setFiles(dst, r.MultipartForm.File)
+}
+
+// Query fills the `query` fields of dst from the query string
+func Query(r *http.Request, dst interface{}) error {
+	return setFields(dst, "query", r.URL.Query())
+}
+
+// Params fills the `param` fields of dst from route parameters
+func Params(params map[string]string, dst interface{}) error {
+	values := make(url.Values, len(params))
+	for key, value := range params {
+		values.Set(key, value)
+	}
+	return setFields(dst, "param", values)
+}
@@ -0,0 +1,175 @@
+package binding
+
+import (
+	"encoding"
+	"fmt"
+	"mime/multipart"
+	"reflect"
+	"strconv"
+	"strings"
+	"time"
+)
+
+var (
+	fileHeaderType  = reflect.TypeOf((*multipart.FileHeader)(nil))
+	fileHeadersType = reflect.TypeOf([]*multipart.FileHeader(nil))
+	timeType        = reflect.TypeOf(time.Time{})
+	unmarshalerType = reflect.TypeOf((*encoding.TextUnmarshaler)(nil)).Elem()
+)
+
+// structValue returns the struct dst points to
+func structValue(dst interface{}) (reflect.Value, error) {
+	v := reflect.ValueOf(dst)
+	if v.Kind() != reflect.Ptr || v.IsNil() || v.Elem().Kind() != reflect.Struct {
+		return reflect.Value{}, fmt.Errorf("binding: destination must be a non-nil pointer to a struct, got %T", dst)
+	}
+	return v.Elem(), nil
+}
+
+// eachField calls fn for every field of v carrying tag, descending into
+// embedded structs
+func eachField(v reflect.Value, tag string, fn func(name string, field reflect.Value) error) error {
+	t := v.Type()
+	for i := 0; i < t.NumField(); i++ {
+		sf := t.Field(i)
+		if !sf.IsExported() {
+			continue
+		}
+
+		name := strings.Split(sf.Tag.Get(tag), ",")[0]
+		if name == "" && sf.Anonymous && sf.Type.Kind() == reflect.Struct {
+			if err := eachField(v.Field(i), tag, fn); err != nil {
+				return err
+			}
+			continue
+		}
+		if name == "" || name == "-" {
+			continue
+		}
+		if err := fn(name, v.Field(i)); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func setFields(dst interface{}, tag string, values map[string][]string) error {
+	v, err := structValue(dst)
+	if err != nil {
+		return err
+	}
+	return eachField(v, tag, func(name string, field reflect.Value) error {
+		vals, ok := values[name]
+		if !ok || len(vals) == 0 || field.Type() == fileHeaderType || field.Type() == fileHeadersType {
+			return nil
+		}
+		if err := setValue(field, vals); err != nil {
+			return &Error{Source: tag, Field: name, Err: err}
+		}
+		return nil
+	})
+}
+
+func setFiles(dst interface{}, files map[string][]*multipart.FileHeader) error {
+	v, err := structValue(dst)
+	if err != nil {
+		return err
+	}
+	return eachField(v, "form", func(name string, field reflect.Value) error {
+		headers := files[name]
+		if len(headers) == 0 {
+			return nil
+		}
+		switch field.Type() {
+		case fileHeaderType:
+			field.Set(reflect.ValueOf(headers[0]))
+		case fileHeadersType:
+			field.Set(reflect.ValueOf(headers))
+		}
+		return nil
+	})
+}
+
+// setValue stores vals in field, all of them for slices, the first one
+// otherwise
+func setValue(field reflect.Value, vals []string) error {
+	if field.Kind() == reflect.Slice && field.Type().Elem().Kind() != reflect.Uint8 && !implementsUnmarshaler(field) {
+		slice := reflect.MakeSlice(field.Type(), len(vals), len(vals))
+		for i, val := range vals {
+			if err := setScalar(slice.Index(i), val); err != nil {
+				return err
+			}
+		}
+		field.Set(slice)
+		return nil
+	}
+	return setScalar(field, vals[0])
+}
+
+func implementsUnmarshaler(v reflect.Value) bool {
+	return v.CanAddr() && v.Addr().Type().Implements(unmarshalerType)
+}
+
+func setScalar(v reflect.Value, s string) error {
+	if v.Kind() == reflect.Ptr {
+		if v.IsNil() {
+			v.Set(reflect.New(v.Type().Elem()))
+		}
+		return setScalar(v.Elem(), s)
+	}
+
+	if v.Type() == timeType {
+		return setTime(v, s)
+	}
+	if implementsUnmarshaler(v) {
+		return v.Addr().Interface().(encoding.TextUnmarshaler).UnmarshalText([]byte(s))
+	}
+
+	switch v.Kind() {
+	case reflect.String:
+		v.SetString(s)
+	case reflect.Bool:
+		if s == "on" { // HTML checkboxes
+			s = "true"
+		}
+		b, err := strconv.ParseBool(s)
+		if err != nil {
+			return fmt.Errorf("%q is not a boolean", s)
+		}
+		v.SetBool(b)
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		n, err := strconv.ParseInt(s, 10, v.Type().Bits())
+		if err != nil {
+			return fmt.Errorf("%q is not an integer", s)
+		}
+		v.SetInt(n)
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		n, err := strconv.ParseUint(s, 10, v.Type().Bits())
+		if err != nil {
+			return fmt.Errorf("%q is not a non-negative integer", s)
+		}
+		v.SetUint(n)
+	case reflect.Float32, reflect.Float64:
+		f, err := strconv.ParseFloat(s, v.Type().Bits())
+		if err != nil {
+			return fmt.Errorf("%q is not a number", s)
+		}
+		v.SetFloat(f)
+	case reflect.Slice:
+		v.SetBytes([]byte(s))
+	default:
+		return fmt.Errorf("cannot bind into a field of type %s", v.Type())
+	}
+	return nil
+}
+
+// setTime accepts RFC 3339 timestamps and plain dates
+func setTime(v reflect.Value, s string) error {
+	for _, layout := range []string{time.RFC3339, "2006-01-02T15:04", "2006-01-02"} {
+		if t, err := time.Parse(layout, s); err == nil {
+			v.Set(reflect.ValueOf(t))
+			return nil
+		}
+	}
+	return fmt.Errorf("%q is not a date or RFC 3339 time", s)
+}
@@ -7,5 +7,5 @@ import (
 
 func InitializeApp() {
 	fmt.Println("Initializing Enzovu Framework...")
-	config.LoadConfig() // Load configurations
+	config.GetConfig() // Load configurations, unless already loaded
 }
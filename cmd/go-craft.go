@@ -18,7 +18,9 @@ var rootCmd = &cobra.Command{
 
 // Initialize the CLI tool with subcommands
 func init() {
-	rootCmd.AddCommand(commands.CreateCmd) // Register the create command
+	rootCmd.AddCommand(commands.CreateCmd)    // Register the create command
+	rootCmd.AddCommand(commands.RouteListCmd) // Register the route:list command
+	rootCmd.AddCommand(commands.ServeCmd)     // Register the serve command
 }
 
 // Main function to execute CLI commands
@@ -6,10 +6,12 @@ import (
 	"os"
 	"strconv"
 	"strings"
+	"time"
 )
 
 type AppConfig struct {
 	Environment string
+	Host        string
 	Port        string
 	Debug       bool
 	Name        string
@@ -25,9 +27,29 @@ type DatabaseConfig struct {
 	Charset  string
 }
 
+// ServerConfig controls the HTTP server
+type ServerConfig struct {
+	TLSCert      string        // certificate file, HTTPS is served when set
+	TLSKey       string        // private key file of TLSCert
+	ReadTimeout  time.Duration // limit for reading a whole request
+	WriteTimeout time.Duration // limit for writing a response
+	IdleTimeout  time.Duration // how long keep-alive connections wait for the next request
+}
+
+// WatchConfig controls the file watcher of the development server
+type WatchConfig struct {
+	Enabled  bool          // rebuild and reload on changes in development
+	Include  []string      // globs of files that trigger actions
+	Exclude  []string      // globs of files and directories to ignore
+	Debounce time.Duration // quiet time that ends a batch of changes
+	Poll     bool          // poll instead of using file system events
+}
+
 type Config struct {
 	App      AppConfig
 	Database DatabaseConfig
+	Server   ServerConfig
+	Watch    WatchConfig
 }
 
 var AppConf *Config
@@ -38,8 +60,9 @@ func LoadConfig() *Config {
 	config := &Config{
 		App: AppConfig{
 			Environment: getEnv("APP_ENV", "development"),
+			Host:        getEnv("APP_HOST", ""),
 			Port:        getEnv("APP_PORT", "8000"),
-			Debug:       getEnvBool("APP_DEBUG", true),
+			Debug:       getEnvBool("APP_DEBUG", false),
 			Name:        getEnv("APP_NAME", "Enzovu App"),
 		},
 		Database: DatabaseConfig{
@@ -51,6 +74,25 @@ func LoadConfig() *Config {
 			Database: getEnv("DB_DATABASE", "enzovu_db"),
 			Charset:  getEnv("DB_CHARSET", "utf8mb4"),
 		},
+		Server: ServerConfig{
+			TLSCert:      getEnv("SERVER_TLS_CERT", ""),
+			TLSKey:       getEnv("SERVER_TLS_KEY", ""),
+			ReadTimeout:  getEnvDuration("SERVER_READ_TIMEOUT", 15*time.Second),
+			WriteTimeout: getEnvDuration("SERVER_WRITE_TIMEOUT", 15*time.Second),
+			IdleTimeout:  getEnvDuration("SERVER_IDLE_TIMEOUT", 60*time.Second),
+		},
+		Watch: WatchConfig{
+			Enabled: getEnvBool("WATCH_ENABLED", true),
+			Include: getEnvList("WATCH_INCLUDE", []string{
+				"**/*.go", "go.mod", "go.sum", "resources/views/**/*.html", "public/**",
+			}),
+			Exclude: getEnvList("WATCH_EXCLUDE", []string{
+				".git/**", "vendor/**", "node_modules/**", "tmp/**", ".vscode/**", ".idea/**",
+				"dist/**", "build/**", "**/*_test.go", "**/.*.swp", "**/*~",
+			}),
+			Debounce: time.Duration(getEnvInt("WATCH_DEBOUNCE_MS", 100)) * time.Millisecond,
+			Poll:     getEnvBool("WATCH_POLL", false),
+		},
 	}
 
 	AppConf = config
@@ -60,6 +102,8 @@ func LoadConfig() *Config {
 	return config
 }
 
+// loadEnvFile sets the variables of the first env file found. Variables
+// already in the environment win, so they can override the file.
 func loadEnvFile() {
 	envFiles := []string{".env.local", ".env"}
 
@@ -93,7 +137,9 @@ func loadEnvFile() {
 					value = value[1 : len(value)-1]
 				}
 
-				os.Setenv(key, value)
+				if _, set := os.LookupEnv(key); !set {
+					os.Setenv(key, value)
+				}
 			}
 		}
 		break // Successfully loaded one file
@@ -125,6 +171,31 @@ func getEnvInt(key string, defaultValue int) int {
 	return defaultValue
 }
 
+// getEnvDuration reads a duration such as "15s" or "2m"
+func getEnvDuration(key string, defaultValue time.Duration) time.Duration {
+	if value := os.Getenv(key); value != "" {
+		if parsed, err := time.ParseDuration(value); err == nil {
+			return parsed
+		}
+	}
+	return defaultValue
+}
+
+// getEnvList reads a comma-separated list
+func getEnvList(key string, defaultValue []string) []string {
+	value := os.Getenv(key)
+	if value == "" {
+		return defaultValue
+	}
+	var list []string
+	for _, item := range strings.Split(value, ",") {
+		if item = strings.TrimSpace(item); item != "" {
+			list = append(list, item)
+		}
+	}
+	return list
+}
+
 // GetConfig returns the global configuration.
 func GetConfig() *Config {
 	if AppConf == nil {
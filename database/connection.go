@@ -104,6 +104,15 @@ func GetDB() *sql.DB {
 	return DB
 }
 
+// Placeholder returns the n-th bind parameter (from 1) in the syntax of
+// the configured driver: $n for PostgreSQL, ? for the others
+func Placeholder(n int) string {
+	if config.GetConfig().Database.Driver == "postgres" {
+		return fmt.Sprintf("$%d", n)
+	}
+	return "?"
+}
+
 // Health check for the database connection
 func HealthCheck() error {
 	if DB == nil {
@@ -0,0 +1,209 @@
+package devserver
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"os"
+	"os/exec"
+	"sync"
+	"time"
+)
+
+// envSupervised marks processes started by a Supervisor
+const envSupervised = "ENZOVU_SUPERVISED"
+
+// File descriptors passed to children, after stdin, stdout and stderr
+const (
+	listenerFD = 3
+	readyFD    = 4
+	errorsFD   = 5
+)
+
+// outputLimit is how much of a child's output is kept for the error page
+const outputLimit = 64 << 10
+
+// Supervised reports whether this process was started by a Supervisor,
+// and should serve on Listener instead of starting its own
+func Supervised() bool {
+	return os.Getenv(envSupervised) == "1"
+}
+
+// Listener returns the listening socket inherited from the supervisor
+func Listener() (net.Listener, error) {
+	if !Supervised() {
+		return nil, errors.New("devserver: not started by a supervisor")
+	}
+	f := os.NewFile(listenerFD, "listener")
+	defer f.Close() // FileListener keeps its own copy
+	return net.FileListener(f)
+}
+
+// Ready tells the supervisor that the process is serving, so it can stop
+// the previous child
+func Ready() error {
+	if !Supervised() {
+		return nil
+	}
+	f := os.NewFile(readyFD, "ready")
+	defer f.Close()
+	_, err := f.Write([]byte{1})
+	return err
+}
+
+// BuildError is a failed build or startup, reported to the child that
+// keeps serving meanwhile
+type BuildError struct {
+	Title   string
+	Details string
+}
+
+var (
+	buildErrors     chan BuildError
+	buildErrorsOnce sync.Once
+)
+
+// BuildErrors delivers the errors of later reloads that failed while this
+// process keeps serving, so they can be shown in the browser. The channel
+// is closed once the supervisor stops reporting, and right away when the
+// process is not supervised.
+func BuildErrors() <-chan BuildError {
+	buildErrorsOnce.Do(func() {
+		buildErrors = make(chan BuildError)
+		if !Supervised() {
+			close(buildErrors)
+			return
+		}
+		go func() {
+			defer close(buildErrors)
+			f := os.NewFile(errorsFD, "errors")
+			defer f.Close()
+			decoder := json.NewDecoder(f)
+			for {
+				var e BuildError
+				if err := decoder.Decode(&e); err != nil {
+					return
+				}
+				buildErrors <- e
+			}
+		}()
+	})
+	return buildErrors
+}
+
+// child is a running application binary
+type child struct {
+	binary string
+	cmd    *exec.Cmd
+	out    *tail
+	errors *os.File // write end of the child's BuildErrors
+	exited chan struct{}
+	err    error // set before exited is closed
+}
+
+// startChild runs binary with the listening socket and waits until it
+// reports Ready. The returned child is non-nil even on error, for its
+// output.
+func startChild(binary string, socket *os.File, timeout time.Duration) (*child, error) {
+	c := &child{binary: binary, out: &tail{}, exited: make(chan struct{})}
+
+	readyR, readyW, err := os.Pipe()
+	if err != nil {
+		return c, err
+	}
+	defer readyR.Close()
+	errorsR, errorsW, err := os.Pipe()
+	if err != nil {
+		readyW.Close()
+		return c, err
+	}
+	defer errorsR.Close()
+
+	c.cmd = exec.Command(binary)
+	c.cmd.Env = append(os.Environ(), envSupervised+"=1")
+	c.cmd.Stdout = io.MultiWriter(os.Stdout, c.out)
+	c.cmd.Stderr = io.MultiWriter(os.Stderr, c.out)
+	c.cmd.ExtraFiles = []*os.File{socket, readyW, errorsR}
+	err = c.cmd.Start()
+	readyW.Close() // the child holds the only write end now
+	if err != nil {
+		errorsW.Close()
+		return c, err
+	}
+	c.errors = errorsW
+	go func() {
+		c.err = c.cmd.Wait()
+		c.errors.Close()
+		close(c.exited)
+	}()
+
+	// Ready writes one byte; the pipe reports EOF if the child exits first
+	ready := make(chan bool, 1)
+	go func() {
+		n, _ := readyR.Read(make([]byte, 1))
+		ready <- n == 1
+	}()
+
+	select {
+	case ok := <-ready:
+		if ok {
+			return c, nil
+		}
+		<-c.exited
+		return c, fmt.Errorf("exited before serving: %v", c.err)
+	case <-time.After(timeout):
+		c.cmd.Process.Kill()
+		<-c.exited
+		return c, fmt.Errorf("not serving after %v", timeout)
+	}
+}
+
+// stop asks the child to shut down, finishing its requests, and kills it
+// when ctx expires
+func (c *child) stop(ctx context.Context) {
+	if err := stopSignal(c.cmd.Process); err != nil {
+		c.cmd.Process.Kill()
+	}
+	select {
+	case <-c.exited:
+	case <-ctx.Done():
+		c.cmd.Process.Kill()
+		<-c.exited
+	}
+}
+
+// report tells the child about a failed reload. A child that does not
+// read its BuildErrors fails the write after a second.
+func (c *child) report(title, details string) error {
+	c.errors.SetWriteDeadline(time.Now().Add(time.Second))
+	return json.NewEncoder(c.errors).Encode(BuildError{Title: title, Details: details})
+}
+
+func (c *child) output() string {
+	return c.out.String()
+}
+
+// tail keeps the last outputLimit bytes written to it
+type tail struct {
+	mu  sync.Mutex
+	buf []byte
+}
+
+func (t *tail) Write(p []byte) (int, error) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.buf = append(t.buf, p...)
+	if over := len(t.buf) - outputLimit; over > 0 {
+		t.buf = append(t.buf[:0], t.buf[over:]...)
+	}
+	return len(p), nil
+}
+
+func (t *tail) String() string {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	return string(t.buf)
+}
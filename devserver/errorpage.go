@@ -0,0 +1,63 @@
+package devserver
+
+import (
+	"fmt"
+	"html"
+	"net/http"
+	"strings"
+	"sync"
+)
+
+// errorPage answers every request with the last build or startup error
+type errorPage struct {
+	mu      sync.RWMutex
+	title   string
+	details string
+}
+
+func setErrorPage(server *http.Server, title, details string) {
+	page := server.Handler.(*errorPage)
+	page.mu.Lock()
+	defer page.mu.Unlock()
+	page.title, page.details = title, details
+}
+
+func (p *errorPage) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	p.mu.RLock()
+	title, details := p.title, p.details
+	p.mu.RUnlock()
+
+	w.Header().Set("Cache-Control", "no-store")
+	if !strings.Contains(r.Header.Get("Accept"), "text/html") {
+		w.Header().Set("Content-Type", "text/plain; charset=utf-8")
+		w.WriteHeader(http.StatusInternalServerError)
+		fmt.Fprintf(w, "%s\n\n%s\n", title, details)
+		return
+	}
+
+	w.Header().Set("Content-Type", "text/html; charset=utf-8")
+	w.WriteHeader(http.StatusInternalServerError)
+	fmt.Fprintf(w, errorPageHTML, html.EscapeString(title), html.EscapeString(title), html.EscapeString(details))
+}
+
+// errorPageHTML refreshes itself, so the application shows up as soon as
+// a rebuild succeeds
+const errorPageHTML = `<!DOCTYPE html>
+<html>
+<head>
+    <meta charset="utf-8">
+    <meta http-equiv="refresh" content="2">
+    <title>%s - Enzovu</title>
+    <style>
+        body { font-family: Arial, sans-serif; margin: 0; padding: 40px; background: #1e1e2e; color: #cdd6f4; }
+        h1 { color: #f38ba8; font-size: 1.6em; }
+        pre { background: #11111b; padding: 20px; border-radius: 6px; overflow-x: auto; line-height: 1.5; }
+        p { color: #a6adc8; }
+    </style>
+</head>
+<body>
+    <h1>🐘 %s</h1>
+    <pre>%s</pre>
+    <p>Fix the error and save; this page reloads when the application is back.</p>
+</body>
+</html>`
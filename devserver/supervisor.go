@@ -0,0 +1,275 @@
+// Package devserver runs the application under a development supervisor.
+// The supervisor owns the listening socket, builds the application with
+// `go build` and runs the binary as a child process that serves on the
+// inherited socket. On every Reload the new binary is started next to the
+// old one, and the old child is only stopped, gracefully, once the new
+// one is serving, so no request is refused or cut off.
+//
+// When the build fails, or the new binary exits before serving, the
+// error is printed and the current child keeps serving. It receives the
+// error on BuildErrors, to show it in the browser until a build succeeds.
+// Without a child, the error itself is served until the next Reload.
+//
+// Passing the socket to children relies on inherited file descriptors,
+// which Windows does not support.
+package devserver
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"runtime"
+	"sync"
+	"sync/atomic"
+	"syscall"
+	"time"
+)
+
+// Config controls a Supervisor
+type Config struct {
+	// Addr is the address to listen on, e.g. ":8000"
+	Addr string
+
+	// Package is the main package to build, "." when empty
+	Package string
+
+	// OutputDir receives the built binaries, "tmp" when empty
+	OutputDir string
+
+	// ReadyTimeout bounds the wait for a new child to serve, 30 seconds
+	// when zero
+	ReadyTimeout time.Duration
+
+	// ShutdownTimeout bounds the graceful shutdown of an old child, 30
+	// seconds when zero. The child is killed afterwards.
+	ShutdownTimeout time.Duration
+
+	// TLSCert and TLSKey make the error page use HTTPS, for applications
+	// that serve HTTPS themselves
+	TLSCert string
+	TLSKey  string
+}
+
+// Supervisor builds and runs the application, see the package comment
+type Supervisor struct {
+	config   Config
+	listener *net.TCPListener
+	socket   *os.File // the listening socket, inherited by children
+
+	reloadMu sync.Mutex // serializes Reload and Shutdown
+	builds   int
+	stopping atomic.Bool // set by Shutdown, see watchChild
+
+	mu        sync.Mutex
+	child     *child
+	errServer *http.Server
+}
+
+// New listens on config.Addr. Nothing is served until Reload.
+func New(config Config) (*Supervisor, error) {
+	if config.Package == "" {
+		config.Package = "."
+	}
+	if config.OutputDir == "" {
+		config.OutputDir = "tmp"
+	}
+	if config.ReadyTimeout == 0 {
+		config.ReadyTimeout = 30 * time.Second
+	}
+	if config.ShutdownTimeout == 0 {
+		config.ShutdownTimeout = 30 * time.Second
+	}
+
+	ln, err := net.Listen("tcp", config.Addr)
+	if err != nil {
+		return nil, err
+	}
+	listener := ln.(*net.TCPListener)
+	socket, err := listener.File()
+	if err != nil {
+		listener.Close()
+		return nil, err
+	}
+	return &Supervisor{config: config, listener: listener, socket: socket}, nil
+}
+
+// Addr returns the address the supervisor listens on
+func (s *Supervisor) Addr() net.Addr {
+	return s.listener.Addr()
+}
+
+// Reload builds the application and swaps the running child for the new
+// binary. On failure the current child keeps serving and is told about
+// the error; when there is none, the error page is served instead.
+func (s *Supervisor) Reload() error {
+	s.reloadMu.Lock()
+	defer s.reloadMu.Unlock()
+
+	s.builds++
+	binary := filepath.Join(s.config.OutputDir, fmt.Sprintf("enzovu-dev-%d", s.builds))
+	if runtime.GOOS == "windows" {
+		binary += ".exe"
+	}
+
+	fmt.Println("🔨 Building...")
+	start := time.Now()
+	if output, err := build(s.config.Package, binary); err != nil {
+		if s.stopping.Load() {
+			// Ctrl+C interrupted the build as well
+			return fmt.Errorf("build interrupted: %w", err)
+		}
+		fmt.Printf("❌ Build failed:\n%s\n", output)
+		s.reloadFailed("Build failed", output)
+		return fmt.Errorf("build failed: %w", err)
+	}
+	fmt.Printf("✅ Built in %v\n", time.Since(start).Round(time.Millisecond))
+
+	next, err := startChild(binary, s.socket, s.config.ReadyTimeout)
+	if err != nil {
+		os.Remove(binary)
+		if s.stopping.Load() {
+			return err
+		}
+		fmt.Printf("❌ Application failed to start: %v\n", err)
+		s.reloadFailed("Application failed to start", next.output())
+		return err
+	}
+
+	s.mu.Lock()
+	previous, errServer := s.child, s.errServer
+	s.child, s.errServer = next, nil
+	s.mu.Unlock()
+
+	go s.watchChild(next)
+	s.stopErrorPage(errServer)
+	s.stopChild(previous)
+	return nil
+}
+
+// Shutdown stops the child and the error page gracefully and closes the
+// listening socket. Call it as soon as Ctrl+C arrives: the terminal sends
+// the interrupt to the children too, and their exits are only taken for
+// the shutdown once it has begun.
+func (s *Supervisor) Shutdown(ctx context.Context) error {
+	s.stopping.Store(true)
+	s.reloadMu.Lock()
+	defer s.reloadMu.Unlock()
+
+	s.mu.Lock()
+	current, errServer := s.child, s.errServer
+	s.child, s.errServer = nil, nil
+	s.mu.Unlock()
+
+	if errServer != nil {
+		errServer.Shutdown(ctx)
+	}
+	if current != nil {
+		current.stop(ctx)
+		os.Remove(current.binary)
+	}
+	s.socket.Close()
+	return s.listener.Close()
+}
+
+// reloadFailed reports a failed Reload to the running child, which keeps
+// serving, or serves the error page when no child can show it
+func (s *Supervisor) reloadFailed(title, details string) {
+	s.mu.Lock()
+	current := s.child
+	s.mu.Unlock()
+	if current != nil && current.report(title, details) == nil {
+		fmt.Println("↩️  Still serving the previous build")
+		return
+	}
+	s.fail(title, details)
+}
+
+// fail replaces the running child with the error page
+func (s *Supervisor) fail(title, details string) {
+	s.mu.Lock()
+	previous, errServer := s.child, s.errServer
+	s.child = nil
+	if errServer == nil {
+		errServer = s.serveErrorPage()
+		s.errServer = errServer
+	}
+	setErrorPage(errServer, title, details)
+	s.mu.Unlock()
+
+	s.stopChild(previous)
+}
+
+// watchChild shows the error page when a child exits on its own, e.g.
+// after a panic. Exits during Shutdown are expected, since a Ctrl+C
+// reaches the child as well.
+func (s *Supervisor) watchChild(c *child) {
+	<-c.exited
+
+	s.mu.Lock()
+	crashed := s.child == c && !s.stopping.Load()
+	s.mu.Unlock()
+	if crashed {
+		fmt.Printf("❌ Application exited: %v\n", c.err)
+		s.fail("Application exited", c.output())
+	}
+}
+
+func (s *Supervisor) stopChild(c *child) {
+	if c == nil {
+		return
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), s.config.ShutdownTimeout)
+	defer cancel()
+	c.stop(ctx)
+	os.Remove(c.binary)
+}
+
+// serveErrorPage serves the error page on a duplicate of the listening
+// socket, so stopping it leaves the socket open for the next child
+func (s *Supervisor) serveErrorPage() *http.Server {
+	server := &http.Server{Handler: &errorPage{}}
+	ln, err := net.FileListener(s.socket)
+	if err != nil {
+		fmt.Printf("❌ Cannot serve the error page: %v\n", err)
+		return server
+	}
+	if s.config.TLSCert != "" {
+		go server.ServeTLS(ln, s.config.TLSCert, s.config.TLSKey)
+	} else {
+		go server.Serve(ln)
+	}
+	return server
+}
+
+func (s *Supervisor) stopErrorPage(server *http.Server) {
+	if server == nil {
+		return
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), s.config.ShutdownTimeout)
+	defer cancel()
+	server.Shutdown(ctx)
+}
+
+// build compiles pkg to binary, returning the compiler output
+func build(pkg, binary string) (string, error) {
+	if err := os.MkdirAll(filepath.Dir(binary), 0o755); err != nil {
+		return err.Error(), err
+	}
+	cmd := exec.Command("go", "build", "-o", binary, pkg)
+	output, err := cmd.CombinedOutput()
+	return string(bytes.TrimSpace(output)), err
+}
+
+// stopSignal asks a child to shut down gracefully
+func stopSignal(p *os.Process) error {
+	if runtime.GOOS == "windows" {
+		return errors.New("signals are not supported on windows")
+	}
+	return p.Signal(syscall.SIGTERM)
+}
@@ -0,0 +1,276 @@
+// Package enzovu provides the Context handler style. A handler takes a
+// *Context and returns an error, which the router hands to its central
+// error handler:
+//
+//	func ShowUser(c *enzovu.Context) error {
+//		id, err := c.ParamInt("id")
+//		if err != nil {
+//			return err // 400 Bad Request
+//		}
+//		user, err := models.FindUser(id)
+//		if err != nil {
+//			return err
+//		}
+//		return c.JSON(user)
+//	}
+//
+// Such handlers are registered with the Ctx variants of the router verbs
+// (GETCtx, POSTCtx, PUTCtx, ...), next to plain http.HandlerFuncs on the
+// usual ones:
+//
+//	router.GETCtx("/users/{id:int}", ShowUser)
+package enzovu
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+
+	"enzovu/binding"
+	"enzovu/render"
+	"enzovu/sse"
+	"enzovu/validation"
+)
+
+// HandlerFunc is a handler written against Context
+type HandlerFunc func(c *Context) error
+
+// Context wraps the request and response of one handler call
+type Context struct {
+	Request  *http.Request
+	Response http.ResponseWriter
+
+	params  map[string]string
+	status  int
+	written bool
+}
+
+// NewContext wraps w and r. The router calls it with the route
+// parameters of r.
+func NewContext(w http.ResponseWriter, r *http.Request, params map[string]string) *Context {
+	return &Context{Request: r, Response: w, params: params}
+}
+
+// Param returns a route parameter, or "" if it is not set
+func (c *Context) Param(name string) string {
+	return c.params[name]
+}
+
+// ParamInt returns a route parameter as an int. The error is a 400
+// HTTPError, so handlers can return it as is.
+func (c *Context) ParamInt(name string) (int, error) {
+	n, err := strconv.Atoi(c.params[name])
+	if err != nil {
+		return 0, &HTTPError{
+			Code:    http.StatusBadRequest,
+			Message: fmt.Sprintf("route parameter %q must be an integer", name),
+			Err:     err,
+		}
+	}
+	return n, nil
+}
+
+// Params returns every route parameter of the request
+func (c *Context) Params() map[string]string {
+	return c.params
+}
+
+// Bind fills dst from the query string, body and route parameters (see
+// package binding), then checks its `validate` tags. Handlers return the
+// error as is: bad input is answered with 400, failed rules with 422 and
+// the validation.Errors bag as JSON.
+//
+//	var input CreateUser
+//	if err := c.Bind(&input); err != nil {
+//		return err
+//	}
+func (c *Context) Bind(dst interface{}) error {
+	if err := binding.Bind(c.Request, dst, c.params); err != nil {
+		return err
+	}
+	return validation.ValidateContext(validation.WithParams(c.Request.Context(), c.params), dst)
+}
+
+// Query returns the first value of a query string parameter
+func (c *Context) Query(name string) string {
+	return c.Request.URL.Query().Get(name)
+}
+
+// QueryDefault returns a query string parameter, or fallback when it is
+// missing or empty
+func (c *Context) QueryDefault(name, fallback string) string {
+	if value := c.Query(name); value != "" {
+		return value
+	}
+	return fallback
+}
+
+// Header returns a request header
+func (c *Context) Header(name string) string {
+	return c.Request.Header.Get(name)
+}
+
+// SetHeader sets a response header
+func (c *Context) SetHeader(name, value string) {
+	c.Response.Header().Set(name, value)
+}
+
+// Status sets the status code written by the next response helper
+//
+//	return c.Status(http.StatusCreated).JSON(user)
+func (c *Context) Status(code int) *Context {
+	c.status = code
+	return c
+}
+
+// Written reports whether a response helper has written the response
+func (c *Context) Written() bool {
+	return c.written
+}
+
+// JSON writes v as JSON, with status 200 unless Status set another. It
+// is indented in development or when the query string has ?pretty.
+func (c *Context) JSON(v interface{}) error {
+	return c.render(func(status int) error {
+		return render.JSON(c.Response, c.Request, status, v)
+	})
+}
+
+// XML writes v as XML (see render.XML)
+func (c *Context) XML(v interface{}) error {
+	return c.render(func(status int) error {
+		return render.XML(c.Response, c.Request, status, v)
+	})
+}
+
+// JSONP writes v wrapped in the function named by the callback query
+// parameter, or as plain JSON without one
+func (c *Context) JSONP(v interface{}) error {
+	return c.render(func(status int) error {
+		return render.JSONP(c.Response, c.Request, status, v)
+	})
+}
+
+// NDJSON streams values as newline-delimited JSON, flushing each one
+//
+//	return c.NDJSON(func(send func(interface{}) error) error {
+//		for _, user := range users {
+//			if err := send(user); err != nil {
+//				return err
+//			}
+//		}
+//		return nil
+//	})
+func (c *Context) NDJSON(fn func(send func(v interface{}) error) error) error {
+	c.written = true // the header goes out before the first value
+	return render.NDJSON(c.Response, c.Request, c.statusOr(http.StatusOK), fn)
+}
+
+// SSE opens a Server-Sent Events stream, runs fn with it and closes it.
+// Errors after the client disconnected are dropped.
+//
+//	return c.SSE(func(s *sse.Stream) error {
+//		return s.Send(sse.Event{ID: "1", Event: "progress", Data: progress})
+//	})
+func (c *Context) SSE(fn func(s *sse.Stream) error, config ...sse.Config) error {
+	s, err := sse.New(c.Response, c.Request, config...)
+	if err != nil {
+		// Nothing was written when the writer cannot stream, so the error
+		// handler can still answer
+		c.written = !errors.Is(err, sse.ErrStreamingUnsupported)
+		return err
+	}
+	c.written = true
+	defer s.Close()
+
+	if err := fn(s); err != nil && !s.Disconnected() {
+		return err
+	}
+	return nil
+}
+
+// View renders a template from resources/views
+func (c *Context) View(view string, data interface{}) error {
+	return c.render(func(status int) error {
+		return render.HTML(c.Response, status, view, data)
+	})
+}
+
+// Negotiate writes data as JSON, XML or plain text, whichever the Accept
+// header prefers, and answers 406 when none of them is acceptable (see
+// render.Respond)
+func (c *Context) Negotiate(data interface{}) error {
+	return c.render(func(status int) error {
+		return render.Respond(c.Response, c.Request, status, data)
+	})
+}
+
+// NegotiateView is Negotiate with HTML from the view template on offer
+// for clients that ask for text/html, such as browsers
+//
+//	return c.NegotiateView("users/show", user)
+func (c *Context) NegotiateView(view string, data interface{}) error {
+	return c.render(func(status int) error {
+		return render.RespondView(c.Response, c.Request, status, view, data)
+	})
+}
+
+// render runs a render function with the status set by Status, or 200.
+// A failed render has written nothing unless the client went away, so
+// the error handler can still answer.
+func (c *Context) render(fn func(status int) error) error {
+	if err := fn(c.statusOr(http.StatusOK)); err != nil {
+		return err
+	}
+	c.written = true
+	return nil
+}
+
+func (c *Context) statusOr(code int) int {
+	if c.status != 0 {
+		return c.status
+	}
+	return code
+}
+
+// String writes a formatted plain text response
+func (c *Context) String(format string, args ...interface{}) error {
+	return c.Blob("text/plain; charset=utf-8", []byte(fmt.Sprintf(format, args...)))
+}
+
+// HTML writes an HTML document
+func (c *Context) HTML(html string) error {
+	return c.Blob("text/html; charset=utf-8", []byte(html))
+}
+
+// Blob writes body with the given content type
+func (c *Context) Blob(contentType string, body []byte) error {
+	c.SetHeader("Content-Type", contentType)
+	c.WriteHeader(http.StatusOK)
+	_, err := c.Response.Write(body)
+	return err
+}
+
+// NoContent writes an empty response, 204 unless Status set another
+func (c *Context) NoContent() error {
+	c.WriteHeader(http.StatusNoContent)
+	return nil
+}
+
+// Redirect redirects to url, with status 302 unless Status set a 3xx code
+func (c *Context) Redirect(url string) error {
+	code := http.StatusFound
+	if c.status >= 300 && c.status < 400 {
+		code = c.status
+	}
+	http.Redirect(c.Response, c.Request, url, code)
+	c.written = true
+	return nil
+}
+
+// WriteHeader writes the status set by Status, or code when none was set
+func (c *Context) WriteHeader(code int) {
+	c.Response.WriteHeader(c.statusOr(code))
+	c.written = true
+}
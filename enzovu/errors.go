@@ -0,0 +1,109 @@
+package enzovu
+
+import (
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"log"
+	"net/http"
+	"strings"
+
+	"enzovu/config"
+)
+
+// HTTPError is an error answered with a given status code and message
+type HTTPError struct {
+	Code    int
+	Message string
+	Err     error // underlying cause, logged but not shown to clients
+}
+
+// NewHTTPError returns an HTTPError. The message defaults to the status
+// text of code.
+//
+//	return enzovu.NewHTTPError(http.StatusForbidden, "you cannot edit this post")
+func NewHTTPError(code int, message ...string) *HTTPError {
+	msg := http.StatusText(code)
+	if len(message) > 0 {
+		msg = strings.Join(message, " ")
+	}
+	return &HTTPError{Code: code, Message: msg}
+}
+
+func (e *HTTPError) Error() string {
+	if e.Err != nil {
+		return e.Message + ": " + e.Err.Error()
+	}
+	return e.Message
+}
+
+func (e *HTTPError) Unwrap() error {
+	return e.Err
+}
+
+// StatusCode returns the HTTP status the error is answered with
+func (e *HTTPError) StatusCode() int {
+	return e.Code
+}
+
+// ErrorHandler turns an error returned by a handler into a response
+type ErrorHandler func(c *Context, err error)
+
+// DefaultErrorHandler answers errors by type:
+//
+//   - *HTTPError uses its code and message
+//   - errors with a StatusCode() int method use that status and, when they
+//     implement json.Marshaler, their own JSON as the body
+//   - sql.ErrNoRows becomes 404
+//   - anything else is logged and becomes 500, showing the error itself
+//     only when APP_DEBUG is explicitly set to true
+//
+// Clients accepting JSON get {"error": message}, others plain text.
+// Errors returned after the response was written are only logged.
+func DefaultErrorHandler(c *Context, err error) {
+	req := c.Request
+	if c.written {
+		log.Printf("❌ %s %s: %v (response already written)", req.Method, req.URL.Path, err)
+		return
+	}
+
+	code, message := http.StatusInternalServerError, http.StatusText(http.StatusInternalServerError)
+	var httpErr *HTTPError
+	var coder interface{ StatusCode() int }
+	switch {
+	case errors.As(err, &httpErr):
+		code, message = httpErr.Code, httpErr.Message
+	case errors.As(err, &coder):
+		code, message = coder.StatusCode(), err.Error()
+	case errors.Is(err, sql.ErrNoRows):
+		code, message = http.StatusNotFound, http.StatusText(http.StatusNotFound)
+	}
+
+	if code >= http.StatusInternalServerError {
+		log.Printf("❌ %s %s: %v", req.Method, req.URL.Path, err)
+		if config.GetConfig().App.Debug {
+			message = err.Error()
+		}
+	}
+
+	var marshaler json.Marshaler
+	if code < http.StatusInternalServerError && errors.As(err, &marshaler) {
+		c.Status(code).JSON(marshaler)
+		return
+	}
+	if WantsJSON(req) {
+		c.Status(code).JSON(map[string]string{"error": message})
+		return
+	}
+	c.Status(code).String("%s\n", message)
+}
+
+// WantsJSON reports whether the client asked for, or sent, JSON
+func WantsJSON(r *http.Request) bool {
+	for _, header := range []string{r.Header.Get("Accept"), r.Header.Get("Content-Type")} {
+		if strings.Contains(header, "application/json") || strings.Contains(header, "+json") {
+			return true
+		}
+	}
+	return false
+}
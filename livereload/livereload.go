@@ -0,0 +1,114 @@
+// Package livereload refreshes browsers in development when templates or
+// static files change. Wrap serves the application with Handler's event
+// stream at Path, and with a small script listening on it injected into
+// HTML pages:
+//
+//	server.Handler = livereload.Wrap(router)
+//
+//	livereload.Reload()                    // reload every page
+//	livereload.ReplaceCSS("css/app.css")   // swap a stylesheet in place
+//	livereload.ShowError(title, details)   // cover pages with an error
+//
+// Each process has its own ID, sent when a page connects, so pages also
+// reload after the application was rebuilt and restarted.
+package livereload
+
+import (
+	"fmt"
+	"os"
+	"sync"
+	"time"
+
+	"enzovu/sse"
+)
+
+// Path is where Handler is expected to be routed; the injected script
+// connects to it
+const Path = "/__livereload"
+
+// retry is how soon browsers reconnect after the application restarted
+const retry = 500 * time.Millisecond
+
+// instance tells this process apart from the one a page connected to before
+var instance = fmt.Sprintf("%d-%d", os.Getpid(), time.Now().UnixNano())
+
+var (
+	mu      sync.Mutex
+	clients = make(map[chan sse.Event]struct{})
+	failure *sse.Event // the error shown on every page, if any
+	closed  = make(chan struct{})
+	stop    sync.Once
+)
+
+// Handler streams reload events to the injected script
+var Handler = sse.Handler(func(s *sse.Stream) error {
+	events := make(chan sse.Event, 8)
+	mu.Lock()
+	clients[events] = struct{}{}
+	if failure != nil {
+		events <- *failure
+	}
+	mu.Unlock()
+	defer func() {
+		mu.Lock()
+		delete(clients, events)
+		mu.Unlock()
+	}()
+
+	if err := s.Send(sse.Event{Event: "hello", Data: instance}); err != nil {
+		return err
+	}
+	for {
+		select {
+		case <-s.Done():
+			return nil
+		case <-closed:
+			return nil
+		case e := <-events:
+			if err := s.Send(e); err != nil {
+				return err
+			}
+		}
+	}
+}, sse.Config{Retry: retry})
+
+// Reload makes every connected page reload
+func Reload() {
+	broadcast(sse.Event{Event: "reload"})
+}
+
+// ReplaceCSS makes connected pages reload the stylesheets at the given
+// paths, relative to the public directory, without reloading the page.
+// Pages not using any of them reload instead.
+func ReplaceCSS(paths ...string) {
+	for _, path := range paths {
+		broadcast(sse.Event{Event: "css", Data: path})
+	}
+}
+
+// ShowError covers connected pages, and pages opened later, with an error
+// such as a failed rebuild. It stays until the process is replaced.
+func ShowError(title, details string) {
+	e := sse.Event{Event: "failure", Data: map[string]string{"title": title, "details": details}}
+	mu.Lock()
+	failure = &e
+	mu.Unlock()
+	broadcast(e)
+}
+
+// Shutdown ends every stream, so a server shutting down does not wait for
+// connected pages. Pass it to http.Server.RegisterOnShutdown.
+func Shutdown() {
+	stop.Do(func() { close(closed) })
+}
+
+func broadcast(e sse.Event) {
+	mu.Lock()
+	defer mu.Unlock()
+	for events := range clients {
+		select {
+		case events <- e:
+		default: // the page stalled, skip it rather than block the watcher
+		}
+	}
+}
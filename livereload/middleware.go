@@ -0,0 +1,186 @@
+package livereload
+
+import (
+	"bytes"
+	"net/http"
+	"strconv"
+	"strings"
+)
+
+// script follows Handler's events: it swaps stylesheets, reloads the page,
+// shows errors over it, and reloads once the stream reconnects to a
+// restarted process. When the stream fails for good, e.g. because the
+// build error page answers, the page reloads to show what the server
+// returns now.
+const script = `<script>(function () {
+	var id, source = new EventSource("` + Path + `");
+	source.addEventListener("hello", function (e) {
+		if (id && id !== e.data) location.reload();
+		id = e.data;
+	});
+	source.addEventListener("reload", function () { location.reload(); });
+	source.addEventListener("css", function (e) {
+		var found = false;
+		document.querySelectorAll('link[rel="stylesheet"]').forEach(function (link) {
+			var url = new URL(link.href, location.href);
+			if (url.pathname.slice(-e.data.length - 1) !== "/" + e.data) return;
+			url.searchParams.set("livereload", Date.now());
+			link.href = url.href;
+			found = true;
+		});
+		if (!found) location.reload();
+	});
+	source.addEventListener("failure", function (e) {
+		var failure = JSON.parse(e.data), overlay = document.getElementById("__livereload_error");
+		if (!overlay) {
+			overlay = document.createElement("div");
+			overlay.id = "__livereload_error";
+			overlay.style.cssText = "position:fixed;inset:0;z-index:2147483647;overflow:auto;padding:40px;" +
+				"background:rgba(30,30,46,.97);color:#cdd6f4;font-family:Arial,sans-serif";
+			overlay.innerHTML = '<h1 style="color:#f38ba8;font-size:1.6em"></h1>' +
+				'<pre style="background:#11111b;padding:20px;border-radius:6px;overflow-x:auto;line-height:1.5"></pre>' +
+				'<p style="color:#a6adc8">The previous build is still running. Fix the error and save; this page reloads when the build succeeds.</p>';
+			document.body.appendChild(overlay);
+		}
+		overlay.querySelector("h1").textContent = "🐘 " + failure.title;
+		overlay.querySelector("pre").textContent = failure.details;
+	});
+	source.onerror = function () {
+		if (source.readyState === EventSource.CLOSED) setTimeout(function () { location.reload(); }, 1000);
+	};
+})();</script>
+`
+
+// Middleware injects the live-reload script into HTML responses, before
+// </body>. Other responses pass through untouched.
+func Middleware(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method == http.MethodHead {
+			next.ServeHTTP(w, r)
+			return
+		}
+		iw := &injectWriter{ResponseWriter: w, status: http.StatusOK}
+		next.ServeHTTP(iw, r)
+		iw.finish()
+	})
+}
+
+// Wrap serves Handler at Path and everything else from app, through
+// Middleware. The development server wraps the application with it, so
+// routes stay free of live-reload wiring.
+func Wrap(app http.Handler) http.Handler {
+	pages := Middleware(app)
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == Path {
+			Handler(w, r)
+			return
+		}
+		pages.ServeHTTP(w, r)
+	})
+}
+
+// injectWriter buffers an HTML body to add the script once it is
+// complete. Whether to inject is decided when the Content-Type is known:
+// at WriteHeader, or at the first Write when the type is sniffed.
+type injectWriter struct {
+	http.ResponseWriter
+	status        int
+	headerPending bool // WriteHeader was called but not yet passed on
+	decided       bool
+	inject        bool
+	body          bytes.Buffer
+}
+
+func (iw *injectWriter) WriteHeader(code int) {
+	if iw.decided {
+		if !iw.inject {
+			iw.ResponseWriter.WriteHeader(code)
+		}
+		return
+	}
+	if code < 200 {
+		iw.ResponseWriter.WriteHeader(code) // informational, e.g. 103 Early Hints
+		return
+	}
+	iw.status = code
+	iw.headerPending = true
+	if iw.Header().Get("Content-Type") != "" {
+		iw.decide()
+	}
+}
+
+func (iw *injectWriter) Write(p []byte) (int, error) {
+	if !iw.decided {
+		if iw.Header().Get("Content-Type") == "" && len(p) > 0 {
+			iw.Header().Set("Content-Type", http.DetectContentType(p))
+		}
+		iw.headerPending = true
+		iw.decide()
+	}
+	if iw.inject {
+		return iw.body.Write(p)
+	}
+	return iw.ResponseWriter.Write(p)
+}
+
+// Flush passes flushes through unless the body is held for injection
+func (iw *injectWriter) Flush() {
+	iw.FlushError()
+}
+
+// FlushError is Flush for http.ResponseController, reporting writers
+// that cannot flush
+func (iw *injectWriter) FlushError() error {
+	if !iw.decided {
+		iw.decide()
+	}
+	if iw.inject {
+		return nil
+	}
+	return http.NewResponseController(iw.ResponseWriter).Flush()
+}
+
+// Unwrap lets http.ResponseController reach the connection's writer, for
+// deadlines and hijacking
+func (iw *injectWriter) Unwrap() http.ResponseWriter {
+	return iw.ResponseWriter
+}
+
+// decide chooses whether to inject and passes the header on otherwise
+func (iw *injectWriter) decide() {
+	iw.decided = true
+	header := iw.Header()
+	iw.inject = strings.HasPrefix(header.Get("Content-Type"), "text/html") &&
+		header.Get("Content-Encoding") == "" &&
+		iw.status != http.StatusNoContent &&
+		iw.status != http.StatusPartialContent &&
+		iw.status != http.StatusNotModified
+	if !iw.inject && iw.headerPending {
+		iw.ResponseWriter.WriteHeader(iw.status)
+	}
+}
+
+// finish writes the buffered body with the script, or a header that was
+// never followed by a body
+func (iw *injectWriter) finish() {
+	if !iw.decided {
+		if iw.headerPending {
+			iw.ResponseWriter.WriteHeader(iw.status)
+		}
+		return
+	}
+	if !iw.inject {
+		return
+	}
+
+	body := iw.body.Bytes()
+	at := bytes.LastIndex(bytes.ToLower(body), []byte("</body>"))
+	if at < 0 {
+		at = len(body)
+	}
+	iw.Header().Set("Content-Length", strconv.Itoa(len(body)+len(script)))
+	iw.ResponseWriter.WriteHeader(iw.status)
+	iw.ResponseWriter.Write(body[:at])
+	iw.ResponseWriter.Write([]byte(script))
+	iw.ResponseWriter.Write(body[at:])
+}
@@ -0,0 +1,303 @@
+// Package render writes handler payloads as JSON, XML, HTML or plain
+// text, in the format the request's Accept header prefers:
+//
+//	func About(w http.ResponseWriter, r *http.Request) {
+//		render.Respond(w, r, http.StatusOK, about)
+//	}
+//
+// JSON and XML are indented in development, and JSON also when the query
+// string has ?pretty. Rendering errors are returned before anything is
+// written, so callers can still answer with an error page.
+package render
+
+import (
+	"bytes"
+	"encoding/json"
+	"encoding/xml"
+	"fmt"
+	"net/http"
+	"reflect"
+	"regexp"
+	"sort"
+	"strings"
+	"unicode"
+
+	"enzovu/config"
+	"enzovu/views"
+)
+
+// Media types offered by Respond and RespondView
+const (
+	MIMEJSON       = "application/json"
+	MIMEXML        = "application/xml"
+	MIMEHTML       = "text/html"
+	MIMEText       = "text/plain"
+	MIMEJavaScript = "application/javascript"
+	MIMENDJSON     = "application/x-ndjson"
+)
+
+// Respond writes data as JSON, XML or plain text, JSON when the client
+// accepts anything, and answers 406 when it accepts none of them
+func Respond(w http.ResponseWriter, r *http.Request, status int, data interface{}) error {
+	return respond(w, r, status, "", data)
+}
+
+// RespondView is Respond with HTML rendered from the view template on
+// offer for clients that ask for text/html, such as browsers
+func RespondView(w http.ResponseWriter, r *http.Request, status int, view string, data interface{}) error {
+	return respond(w, r, status, view, data)
+}
+
+func respond(w http.ResponseWriter, r *http.Request, status int, view string, data interface{}) error {
+	offers := []string{MIMEJSON, MIMEXML, MIMEText}
+	if view != "" {
+		// After JSON, so */* gets JSON while browsers, which ask for
+		// text/html by name, get the view
+		offers = []string{MIMEJSON, MIMEHTML, MIMEXML, MIMEText}
+	}
+
+	switch Negotiate(r, offers...) {
+	case MIMEHTML:
+		return HTML(w, status, view, data)
+	case MIMEJSON:
+		return JSON(w, r, status, data)
+	case MIMEXML:
+		return XML(w, r, status, data)
+	case MIMEText:
+		return Text(w, status, data)
+	}
+	NotAcceptable(w, offers...)
+	return nil
+}
+
+// NotAcceptable answers 406 listing the media types that are available
+func NotAcceptable(w http.ResponseWriter, offers ...string) {
+	w.Header().Set("Content-Type", "text/plain; charset=utf-8")
+	w.WriteHeader(http.StatusNotAcceptable)
+	fmt.Fprintf(w, "Not Acceptable, available formats: %s\n", strings.Join(offers, ", "))
+}
+
+// pretty reports whether output should be indented for reading
+func pretty(r *http.Request) bool {
+	return config.IsDevelopment() || r != nil && r.URL.Query().Has("pretty")
+}
+
+// JSON writes v as JSON
+func JSON(w http.ResponseWriter, r *http.Request, status int, v interface{}) error {
+	body, err := marshalJSON(r, v)
+	if err != nil {
+		return err
+	}
+	return Blob(w, status, MIMEJSON, body)
+}
+
+func marshalJSON(r *http.Request, v interface{}) ([]byte, error) {
+	var body []byte
+	var err error
+	if pretty(r) {
+		body, err = json.MarshalIndent(v, "", "  ")
+	} else {
+		body, err = json.Marshal(v)
+	}
+	return append(body, '\n'), err
+}
+
+// XML writes v as XML. Maps, slices and plain values are wrapped in a
+// <response> element, slice entries are <item> elements and nil is an
+// empty element. Map keys must be valid XML names.
+func XML(w http.ResponseWriter, r *http.Request, status int, v interface{}) error {
+	var value interface{} = v
+	if kind := reflect.Indirect(reflect.ValueOf(v)).Kind(); kind != reflect.Struct {
+		value = xmlValue{reflect.ValueOf(v)}
+	}
+
+	var body []byte
+	var err error
+	if pretty(r) {
+		body, err = xml.MarshalIndent(value, "", "  ")
+	} else {
+		body, err = xml.Marshal(value)
+	}
+	if err != nil {
+		return err
+	}
+	return Blob(w, status, MIMEXML+"; charset=utf-8", append([]byte(xml.Header), append(body, '\n')...))
+}
+
+// xmlValue encodes the maps and slices encoding/xml cannot
+type xmlValue struct {
+	v reflect.Value
+}
+
+func (x xmlValue) MarshalXML(e *xml.Encoder, start xml.StartElement) error {
+	if start.Name.Local == "xmlValue" {
+		start.Name.Local = "response"
+	}
+
+	v := x.v
+	for v.Kind() == reflect.Ptr || v.Kind() == reflect.Interface {
+		if v.IsNil() {
+			return e.EncodeElement("", start)
+		}
+		v = v.Elem()
+	}
+	if !v.IsValid() {
+		return e.EncodeElement("", start)
+	}
+
+	switch {
+	case v.Kind() == reflect.Map:
+		keys := v.MapKeys()
+		sort.Slice(keys, func(i, j int) bool {
+			return fmt.Sprint(keys[i].Interface()) < fmt.Sprint(keys[j].Interface())
+		})
+		if err := e.EncodeToken(start); err != nil {
+			return err
+		}
+		for _, key := range keys {
+			name := fmt.Sprint(key.Interface())
+			if !xmlName(name) {
+				return fmt.Errorf("render: map key %q is not a valid XML element name", name)
+			}
+			child := xml.StartElement{Name: xml.Name{Local: name}}
+			if err := e.EncodeElement(xmlValue{v.MapIndex(key)}, child); err != nil {
+				return err
+			}
+		}
+		return e.EncodeToken(start.End())
+	case (v.Kind() == reflect.Slice || v.Kind() == reflect.Array) && v.Type().Elem().Kind() != reflect.Uint8:
+		if err := e.EncodeToken(start); err != nil {
+			return err
+		}
+		for i := 0; i < v.Len(); i++ {
+			if err := e.EncodeElement(xmlValue{v.Index(i)}, xml.StartElement{Name: xml.Name{Local: "item"}}); err != nil {
+				return err
+			}
+		}
+		return e.EncodeToken(start.End())
+	}
+	return e.EncodeElement(v.Interface(), start)
+}
+
+// xmlName reports whether name can be an element name: a letter or
+// underscore, then letters, digits, underscores, hyphens and dots
+func xmlName(name string) bool {
+	for i, r := range name {
+		switch {
+		case unicode.IsLetter(r) || r == '_':
+		case i > 0 && (unicode.IsDigit(r) || r == '-' || r == '.'):
+		default:
+			return false
+		}
+	}
+	return name != ""
+}
+
+// Text writes v as plain text: strings, errors and Stringers as they
+// are, maps as sorted "key: value" lines, anything else with %v
+func Text(w http.ResponseWriter, status int, v interface{}) error {
+	var text string
+	switch t := v.(type) {
+	case string:
+		text = t
+	case []byte:
+		text = string(t)
+	case error:
+		text = t.Error()
+	case fmt.Stringer:
+		text = t.String()
+	default:
+		if rv := reflect.ValueOf(v); rv.Kind() == reflect.Map {
+			lines := make([]string, 0, rv.Len())
+			for _, key := range rv.MapKeys() {
+				lines = append(lines, fmt.Sprintf("%v: %v", key.Interface(), rv.MapIndex(key).Interface()))
+			}
+			sort.Strings(lines)
+			text = strings.Join(lines, "\n")
+		} else {
+			text = fmt.Sprintf("%v", v)
+		}
+	}
+	if !strings.HasSuffix(text, "\n") {
+		text += "\n"
+	}
+	return Blob(w, status, MIMEText+"; charset=utf-8", []byte(text))
+}
+
+// HTML renders the view template from resources/views with data
+func HTML(w http.ResponseWriter, status int, view string, data interface{}) error {
+	var buf bytes.Buffer
+	if err := views.Execute(&buf, view, data); err != nil {
+		return err
+	}
+	return Blob(w, status, MIMEHTML+"; charset=utf-8", buf.Bytes())
+}
+
+// callbackName limits JSONP callbacks to JavaScript identifiers and
+// property paths such as jQuery123.done
+var callbackName = regexp.MustCompile(`^[A-Za-z_$][A-Za-z0-9_$]*(\.[A-Za-z_$][A-Za-z0-9_$]*)*$`)
+
+// JSONP wraps v in the function named by the callback query parameter,
+// or writes plain JSON when there is none. Invalid names are answered
+// with 400.
+func JSONP(w http.ResponseWriter, r *http.Request, status int, v interface{}) error {
+	callback := r.URL.Query().Get("callback")
+	if callback == "" {
+		return JSON(w, r, status, v)
+	}
+	if !callbackName.MatchString(callback) {
+		http.Error(w, "invalid JSONP callback name", http.StatusBadRequest)
+		return nil
+	}
+
+	body, err := marshalJSON(r, v)
+	if err != nil {
+		return err
+	}
+	// The leading comment defuses content sniffing attacks on the callback
+	script := fmt.Sprintf("/**/ typeof %[1]s === 'function' && %[1]s(%s);\n", callback, bytes.TrimSpace(body))
+	w.Header().Set("X-Content-Type-Options", "nosniff")
+	return Blob(w, status, MIMEJavaScript+"; charset=utf-8", []byte(script))
+}
+
+// NDJSON streams newline-delimited JSON. fn calls send once per value;
+// every value is flushed to the client as it is sent, and send fails
+// once the client has gone away.
+//
+//	render.NDJSON(w, r, http.StatusOK, func(send func(interface{}) error) error {
+//		for rows.Next() {
+//			...
+//			if err := send(user); err != nil {
+//				return err
+//			}
+//		}
+//		return rows.Err()
+//	})
+func NDJSON(w http.ResponseWriter, r *http.Request, status int, fn func(send func(v interface{}) error) error) error {
+	w.Header().Set("Content-Type", MIMENDJSON)
+	w.Header().Set("X-Content-Type-Options", "nosniff")
+	w.WriteHeader(status)
+
+	rc := http.NewResponseController(w)
+	encoder := json.NewEncoder(w)
+	return fn(func(v interface{}) error {
+		if err := r.Context().Err(); err != nil {
+			return err
+		}
+		if err := encoder.Encode(v); err != nil {
+			return err
+		}
+		if err := rc.Flush(); err != nil && err != http.ErrNotSupported {
+			return err
+		}
+		return nil
+	})
+}
+
+// Blob writes body with the given content type and status
+func Blob(w http.ResponseWriter, status int, contentType string, body []byte) error {
+	w.Header().Set("Content-Type", contentType)
+	w.WriteHeader(status)
+	_, err := w.Write(body)
+	return err
+}
@@ -0,0 +1,203 @@
+package routes
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"log"
+	"net/http"
+	"reflect"
+	"strings"
+
+	"enzovu/database"
+)
+
+// ModelsKey holds the models resolved from route parameters
+const ModelsKey contextKey = "models"
+
+// ErrModelNotFound is returned by resolvers when no model matches the
+// parameter value. The router answers such requests with its NotFound
+// handler.
+var ErrModelNotFound = errors.New("routes: model not found")
+
+// Resolver loads the model a route parameter value refers to
+type Resolver func(r *http.Request, value string) (interface{}, error)
+
+type bindOptions struct {
+	table  string
+	column string
+}
+
+// BindOption customises how Model looks up a row
+type BindOption func(*bindOptions)
+
+// ByColumn looks the model up by column instead of its primary key,
+// e.g. ByColumn("slug")
+func ByColumn(column string) BindOption {
+	return func(o *bindOptions) {
+		o.column = column
+	}
+}
+
+// Table overrides the table the model is loaded from
+func Table(table string) BindOption {
+	return func(o *bindOptions) {
+		o.table = table
+	}
+}
+
+// Bind resolves the route parameter param with a custom resolver for
+// every route that declares it. Bindings are part of the composed route
+// chains, so Bind panics once the router is frozen.
+func (r *Router) Bind(param string, resolver Resolver) {
+	if r.frozen.Load() {
+		panic("routes: Bind called after the router was frozen; bind parameters before serving requests")
+	}
+	if r.bindings == nil {
+		r.bindings = make(map[string]Resolver)
+	}
+	r.bindings[param] = resolver
+}
+
+// Model binds the route parameter param to a model type, loading the row
+// from database.DB by primary key ("id") unless ByColumn says otherwise.
+// model is a struct or pointer to struct, e.g. models.User{}. The table
+// comes from the model's TableName method, falling back to the
+// lower-cased type name plus "s". Columns are read from `db` tags, then
+// `json` tags, then the lower-cased field name.
+//
+//	router.Model("user", models.User{})
+//	router.GET("/users/{user}", func(w http.ResponseWriter, r *http.Request) {
+//		user, _ := routes.GetModel[models.User](r, "user")
+//	})
+func (r *Router) Model(param string, model interface{}, options ...BindOption) {
+	t := reflect.TypeOf(model)
+	for t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+	if t.Kind() != reflect.Struct {
+		panic(fmt.Sprintf("routes: cannot bind {%s} to non-struct type %s", param, t))
+	}
+
+	opts := bindOptions{column: "id", table: tableName(t)}
+	for _, option := range options {
+		option(&opts)
+	}
+
+	columns, _ := modelColumns(t)
+	query := fmt.Sprintf("SELECT %s FROM %s WHERE %s = %s LIMIT 1",
+		strings.Join(columns, ", "), opts.table, opts.column, database.Placeholder(1))
+
+	r.Bind(param, func(req *http.Request, value string) (interface{}, error) {
+		db := database.GetDB()
+		if db == nil {
+			return nil, fmt.Errorf("routes: binding {%s} needs a database connection", param)
+		}
+
+		m := reflect.New(t)
+		_, fields := modelColumns(t)
+		dest := make([]interface{}, len(fields))
+		for i, index := range fields {
+			dest[i] = m.Elem().FieldByIndex(index).Addr().Interface()
+		}
+
+		err := db.QueryRowContext(req.Context(), query, value).Scan(dest...)
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, ErrModelNotFound
+		}
+		if err != nil {
+			return nil, err
+		}
+		return m.Interface(), nil
+	})
+}
+
+// GetModel returns the model bound to the route parameter param
+func GetModel[T any](r *http.Request, param string) (*T, bool) {
+	m, ok := GetModels(r)[param].(*T)
+	return m, ok
+}
+
+// GetModels returns every model bound for the current request
+func GetModels(r *http.Request) map[string]interface{} {
+	if models, ok := r.Context().Value(ModelsKey).(map[string]interface{}); ok {
+		return models
+	}
+	return make(map[string]interface{})
+}
+
+// bindingHandler resolves the bound parameters of route before calling
+// next, answering 404 when a model does not exist
+func (r *Router) bindingHandler(route *Route, next http.Handler) http.Handler {
+	var params []string
+	for _, name := range route.ParamNames {
+		if r.bindings[name] != nil {
+			params = append(params, name)
+		}
+	}
+	if len(params) == 0 {
+		return next
+	}
+
+	return http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		values := GetParams(req)
+		models := make(map[string]interface{}, len(params))
+
+		for _, name := range params {
+			value, ok := values[name]
+			if !ok {
+				continue // optional parameter left out
+			}
+
+			model, err := r.bindings[name](req, value)
+			if errors.Is(err, ErrModelNotFound) || err == nil && model == nil {
+				r.serveNotFound(w, req)
+				return
+			}
+			if err != nil {
+				log.Printf("❌ Failed to resolve {%s}: %v", name, err)
+				http.Error(w, http.StatusText(http.StatusInternalServerError), http.StatusInternalServerError)
+				return
+			}
+			models[name] = model
+		}
+
+		ctx := context.WithValue(req.Context(), ModelsKey, models)
+		next.ServeHTTP(w, req.WithContext(ctx))
+	})
+}
+
+// tableName uses the model's TableName method when it has one
+func tableName(t reflect.Type) string {
+	if namer, ok := reflect.New(t).Interface().(interface{ TableName() string }); ok {
+		return namer.TableName()
+	}
+	return strings.ToLower(t.Name()) + "s"
+}
+
+// modelColumns maps the exported fields of t to column names
+func modelColumns(t reflect.Type) ([]string, [][]int) {
+	var columns []string
+	var fields [][]int
+	for _, field := range reflect.VisibleFields(t) {
+		if !field.IsExported() || field.Anonymous {
+			continue
+		}
+
+		column := strings.Split(field.Tag.Get("db"), ",")[0]
+		if column == "" {
+			column = strings.Split(field.Tag.Get("json"), ",")[0]
+		}
+		if column == "-" {
+			continue
+		}
+		if column == "" {
+			column = strings.ToLower(field.Name)
+		}
+
+		columns = append(columns, column)
+		fields = append(fields, field.Index)
+	}
+	return columns, fields
+}
@@ -0,0 +1,136 @@
+package routes
+
+import (
+	"encoding/hex"
+	"fmt"
+	"regexp"
+	"strconv"
+)
+
+// constraint restricts the values a route parameter accepts. Built-in
+// types are referenced by name, anything else is compiled as a regex that
+// must match the whole segment.
+type constraint struct {
+	rule  string
+	match func(string) bool
+}
+
+var builtinConstraints = map[string]func(string) bool{
+	"int":   isInt,
+	"uuid":  isUUID,
+	"alpha": isAlpha,
+	"alnum": isAlnum,
+	"slug":  isSlug,
+}
+
+func newConstraint(rule string) (*constraint, error) {
+	if rule == "" {
+		return nil, fmt.Errorf("empty constraint")
+	}
+	if match, ok := builtinConstraints[rule]; ok {
+		return &constraint{rule: rule, match: match}, nil
+	}
+
+	re, err := regexp.Compile("^(?:" + rule + ")$")
+	if err != nil {
+		return nil, fmt.Errorf("invalid constraint %q: %w", rule, err)
+	}
+	return &constraint{rule: rule, match: re.MatchString}, nil
+}
+
+// matches reports whether value satisfies c. A nil constraint accepts
+// any value.
+func (c *constraint) matches(value string) bool {
+	return c == nil || c.match(value)
+}
+
+func (c *constraint) String() string {
+	if c == nil {
+		return ""
+	}
+	return c.rule
+}
+
+// isInt accepts non-negative integers that fit in an int64
+func isInt(s string) bool {
+	for i := 0; i < len(s); i++ {
+		if s[i] < '0' || s[i] > '9' {
+			return false
+		}
+	}
+	_, err := strconv.ParseInt(s, 10, 64)
+	return err == nil
+}
+
+func isAlpha(s string) bool {
+	for i := 0; i < len(s); i++ {
+		c := s[i]
+		if !(c >= 'a' && c <= 'z' || c >= 'A' && c <= 'Z') {
+			return false
+		}
+	}
+	return s != ""
+}
+
+func isAlnum(s string) bool {
+	for i := 0; i < len(s); i++ {
+		c := s[i]
+		if !(c >= 'a' && c <= 'z' || c >= 'A' && c <= 'Z' || c >= '0' && c <= '9') {
+			return false
+		}
+	}
+	return s != ""
+}
+
+// isSlug accepts lowercase words separated by single hyphens
+func isSlug(s string) bool {
+	if s == "" || s[0] == '-' || s[len(s)-1] == '-' {
+		return false
+	}
+	for i := 0; i < len(s); i++ {
+		c := s[i]
+		switch {
+		case c >= 'a' && c <= 'z', c >= '0' && c <= '9':
+		case c == '-' && s[i-1] != '-':
+		default:
+			return false
+		}
+	}
+	return true
+}
+
+func isUUID(s string) bool {
+	_, err := ParseUUID(s)
+	return err == nil
+}
+
+// UUID is a 128-bit identifier in the canonical 8-4-4-4-12 hex form.
+type UUID [16]byte
+
+// ParseUUID parses a canonical, hyphenated UUID string.
+func ParseUUID(s string) (UUID, error) {
+	var u UUID
+	if len(s) != 36 || s[8] != '-' || s[13] != '-' || s[18] != '-' || s[23] != '-' {
+		return u, fmt.Errorf("invalid UUID %q", s)
+	}
+
+	raw := s[0:8] + s[9:13] + s[14:18] + s[19:23] + s[24:36]
+	if _, err := hex.Decode(u[:], []byte(raw)); err != nil {
+		return u, fmt.Errorf("invalid UUID %q", s)
+	}
+	return u, nil
+}
+
+func (u UUID) String() string {
+	buf := make([]byte, 36)
+	hex.Encode(buf[0:8], u[0:4])
+	buf[8] = '-'
+	hex.Encode(buf[9:13], u[4:6])
+	buf[13] = '-'
+	hex.Encode(buf[14:18], u[6:8])
+	buf[18] = '-'
+	hex.Encode(buf[19:23], u[8:10])
+	buf[23] = '-'
+	hex.Encode(buf[24:], u[10:])
+	return string(buf)
+}
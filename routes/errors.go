@@ -0,0 +1,160 @@
+package routes
+
+import (
+	"net/http"
+	"strings"
+)
+
+// errorScope holds the error handlers a route group overrides for the
+// requests below its prefix
+type errorScope struct {
+	host             *hostRoutes
+	prefix           string
+	notFound         http.Handler
+	methodNotAllowed http.Handler
+	panicHandler     func(http.ResponseWriter, *http.Request, interface{})
+
+	// chains of the handlers above with the global middlewares, built
+	// by Router.Freeze
+	notFoundChain         http.Handler
+	methodNotAllowedChain http.Handler
+}
+
+// NotFound sets the handler for unmatched requests below the group's
+// prefix, e.g. JSON 404s under /api
+func (rg *RouteGroup) NotFound(handler http.Handler) {
+	rg.errorScope().notFound = handler
+}
+
+// MethodNotAllowed sets the 405 handler for requests below the group's
+// prefix. The Allow header is already set when it runs.
+func (rg *RouteGroup) MethodNotAllowed(handler http.Handler) {
+	rg.errorScope().methodNotAllowed = handler
+}
+
+// PanicHandler recovers panics raised by requests below the group's prefix
+func (rg *RouteGroup) PanicHandler(handler func(http.ResponseWriter, *http.Request, interface{})) {
+	rg.errorScope().panicHandler = handler
+}
+
+func (rg *RouteGroup) errorScope() *errorScope {
+	rg.router.mustNotBeFrozen("group error handler set")
+	prefix := strings.TrimSuffix(rg.prefix, "/")
+	for _, scope := range rg.router.scopes {
+		if scope.host == rg.host && scope.prefix == prefix {
+			return scope
+		}
+	}
+	scope := &errorScope{host: rg.host, prefix: prefix}
+	rg.router.scopes = append(rg.router.scopes, scope)
+	return scope
+}
+
+// findScope returns the most specific scope covering path on host among
+// those pick accepts
+func (r *Router) findScope(host *hostRoutes, path string, pick func(*errorScope) bool) *errorScope {
+	var best *errorScope
+	for _, scope := range r.scopes {
+		if scope.host != host || !pick(scope) {
+			continue
+		}
+		if path != scope.prefix && !strings.HasPrefix(path, scope.prefix+"/") {
+			continue
+		}
+		if best == nil || len(scope.prefix) > len(best.prefix) {
+			best = scope
+		}
+	}
+	return best
+}
+
+// notFoundHandler returns the 404 handler of scope, or the router's when
+// scope is nil
+func (r *Router) notFoundHandler(scope *errorScope) http.Handler {
+	if scope != nil {
+		return scope.notFound
+	}
+	if r.NotFound != nil {
+		return r.NotFound
+	}
+	return http.HandlerFunc(http.NotFound)
+}
+
+// serveNotFound answers req with the NotFound handler in effect for it,
+// for handlers that discover a miss themselves such as Static
+func (r *Router) serveNotFound(w http.ResponseWriter, req *http.Request) {
+	var ps []Param
+	host, _ := r.matchHost(req, &ps)
+	r.notFoundHandler(r.notFoundScope(host, req.URL.Path)).ServeHTTP(w, req)
+}
+
+// notFoundChainFor returns the composed 404 chain in effect for path
+func (r *Router) notFoundChainFor(host *hostRoutes, path string) http.Handler {
+	if scope := r.notFoundScope(host, path); scope != nil {
+		return scope.notFoundChain
+	}
+	return r.notFoundChain
+}
+
+func (r *Router) notFoundScope(host *hostRoutes, path string) *errorScope {
+	return r.findScope(host, path, func(s *errorScope) bool { return s.notFound != nil })
+}
+
+// methodNotAllowedHandler returns the 405 handler of scope, or the
+// router's when scope is nil
+func (r *Router) methodNotAllowedHandler(scope *errorScope) http.Handler {
+	if scope != nil {
+		return scope.methodNotAllowed
+	}
+	if r.MethodNotAllowed != nil {
+		return r.MethodNotAllowed
+	}
+	return http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		http.Error(w, http.StatusText(http.StatusMethodNotAllowed), http.StatusMethodNotAllowed)
+	})
+}
+
+// methodNotAllowedChainFor returns the composed 405 chain in effect for path
+func (r *Router) methodNotAllowedChainFor(host *hostRoutes, path string) http.Handler {
+	if scope := r.findScope(host, path, func(s *errorScope) bool { return s.methodNotAllowed != nil }); scope != nil {
+		return scope.methodNotAllowedChain
+	}
+	return r.methodNotAllowedChain
+}
+
+// recovers reports whether any panic handler is configured
+func (r *Router) recovers() bool {
+	if r.PanicHandler != nil {
+		return true
+	}
+	for _, scope := range r.scopes {
+		if scope.panicHandler != nil {
+			return true
+		}
+	}
+	return false
+}
+
+// recoverPanic hands a recovered panic to the most specific panic handler.
+// http.ErrAbortHandler is re-raised so net/http can abort the response.
+func (r *Router) recoverPanic(w http.ResponseWriter, req *http.Request) {
+	rcv := recover()
+	if rcv == nil {
+		return
+	}
+	if rcv == http.ErrAbortHandler {
+		panic(rcv)
+	}
+
+	var ps []Param
+	host, _ := r.matchHost(req, &ps)
+	if scope := r.findScope(host, req.URL.Path, func(s *errorScope) bool { return s.panicHandler != nil }); scope != nil {
+		scope.panicHandler(w, req, rcv)
+		return
+	}
+	if r.PanicHandler != nil {
+		r.PanicHandler(w, req, rcv)
+		return
+	}
+	panic(rcv)
+}
@@ -0,0 +1,137 @@
+package routes
+
+import (
+	"errors"
+	"net/http"
+	"strings"
+
+	"enzovu/enzovu"
+)
+
+// Handlers written against enzovu.Context are registered with the Ctx
+// variants of the verbs, so a handler of the wrong type fails to build.
+// A returned error goes to Router.ErrorHandler:
+//
+//	router.GET("/", controllers.Home)
+//	router.GETCtx("/users/{id:int}", func(c *enzovu.Context) error {
+//		return c.JSON(map[string]string{"id": c.Param("id")})
+//	})
+
+// contextHandler calls h with a Context and answers a returned error
+// through the router's error handler
+func (r *Router) contextHandler(h enzovu.HandlerFunc) http.HandlerFunc {
+	if h == nil {
+		return nil
+	}
+	return func(w http.ResponseWriter, req *http.Request) {
+		c := enzovu.NewContext(w, req, GetParams(req))
+		if err := h(c); err != nil {
+			r.handleError(c, err)
+		}
+	}
+}
+
+func (r *Router) handleError(c *enzovu.Context, err error) {
+	if errors.Is(err, ErrModelNotFound) {
+		err = &enzovu.HTTPError{Code: http.StatusNotFound, Message: http.StatusText(http.StatusNotFound), Err: err}
+	}
+	if r.ErrorHandler != nil {
+		r.ErrorHandler(c, err)
+		return
+	}
+	enzovu.DefaultErrorHandler(c, err)
+}
+
+// AddRouteCtx registers a Context handler for method and pattern
+func (r *Router) AddRouteCtx(method, pattern string, handler enzovu.HandlerFunc, middlewares ...func(http.Handler) http.Handler) *Route {
+	return r.addRoute(nil, method, pattern, r.contextHandler(handler), handlerName(handler), middlewares)
+}
+
+func (r *Router) GETCtx(pattern string, handler enzovu.HandlerFunc, middlewares ...func(http.Handler) http.Handler) *Route {
+	return r.AddRouteCtx("GET", pattern, handler, middlewares...)
+}
+
+func (r *Router) POSTCtx(pattern string, handler enzovu.HandlerFunc, middlewares ...func(http.Handler) http.Handler) *Route {
+	return r.AddRouteCtx("POST", pattern, handler, middlewares...)
+}
+
+func (r *Router) PUTCtx(pattern string, handler enzovu.HandlerFunc, middlewares ...func(http.Handler) http.Handler) *Route {
+	return r.AddRouteCtx("PUT", pattern, handler, middlewares...)
+}
+
+func (r *Router) DELETECtx(pattern string, handler enzovu.HandlerFunc, middlewares ...func(http.Handler) http.Handler) *Route {
+	return r.AddRouteCtx("DELETE", pattern, handler, middlewares...)
+}
+
+func (r *Router) PATCHCtx(pattern string, handler enzovu.HandlerFunc, middlewares ...func(http.Handler) http.Handler) *Route {
+	return r.AddRouteCtx("PATCH", pattern, handler, middlewares...)
+}
+
+func (r *Router) HEADCtx(pattern string, handler enzovu.HandlerFunc, middlewares ...func(http.Handler) http.Handler) *Route {
+	return r.AddRouteCtx("HEAD", pattern, handler, middlewares...)
+}
+
+func (r *Router) OPTIONSCtx(pattern string, handler enzovu.HandlerFunc, middlewares ...func(http.Handler) http.Handler) *Route {
+	return r.AddRouteCtx("OPTIONS", pattern, handler, middlewares...)
+}
+
+// AnyCtx registers the Context handler for every common HTTP method
+func (r *Router) AnyCtx(pattern string, handler enzovu.HandlerFunc, middlewares ...func(http.Handler) http.Handler) []*Route {
+	return r.MatchCtx(anyMethods, pattern, handler, middlewares...)
+}
+
+// MatchCtx registers the Context handler for each of the given methods
+func (r *Router) MatchCtx(methods []string, pattern string, handler enzovu.HandlerFunc, middlewares ...func(http.Handler) http.Handler) []*Route {
+	routes := make([]*Route, 0, len(methods))
+	for _, method := range methods {
+		routes = append(routes, r.AddRouteCtx(strings.ToUpper(method), pattern, handler, middlewares...))
+	}
+	return routes
+}
+
+// AddRouteCtx registers a Context handler below the group's prefix
+func (rg *RouteGroup) AddRouteCtx(method, pattern string, handler enzovu.HandlerFunc, middlewares ...func(http.Handler) http.Handler) *Route {
+	return rg.addRoute(method, pattern, rg.router.contextHandler(handler), handlerName(handler), middlewares)
+}
+
+func (rg *RouteGroup) GETCtx(pattern string, handler enzovu.HandlerFunc, middlewares ...func(http.Handler) http.Handler) *Route {
+	return rg.AddRouteCtx("GET", pattern, handler, middlewares...)
+}
+
+func (rg *RouteGroup) POSTCtx(pattern string, handler enzovu.HandlerFunc, middlewares ...func(http.Handler) http.Handler) *Route {
+	return rg.AddRouteCtx("POST", pattern, handler, middlewares...)
+}
+
+func (rg *RouteGroup) PUTCtx(pattern string, handler enzovu.HandlerFunc, middlewares ...func(http.Handler) http.Handler) *Route {
+	return rg.AddRouteCtx("PUT", pattern, handler, middlewares...)
+}
+
+func (rg *RouteGroup) DELETECtx(pattern string, handler enzovu.HandlerFunc, middlewares ...func(http.Handler) http.Handler) *Route {
+	return rg.AddRouteCtx("DELETE", pattern, handler, middlewares...)
+}
+
+func (rg *RouteGroup) PATCHCtx(pattern string, handler enzovu.HandlerFunc, middlewares ...func(http.Handler) http.Handler) *Route {
+	return rg.AddRouteCtx("PATCH", pattern, handler, middlewares...)
+}
+
+func (rg *RouteGroup) HEADCtx(pattern string, handler enzovu.HandlerFunc, middlewares ...func(http.Handler) http.Handler) *Route {
+	return rg.AddRouteCtx("HEAD", pattern, handler, middlewares...)
+}
+
+func (rg *RouteGroup) OPTIONSCtx(pattern string, handler enzovu.HandlerFunc, middlewares ...func(http.Handler) http.Handler) *Route {
+	return rg.AddRouteCtx("OPTIONS", pattern, handler, middlewares...)
+}
+
+// AnyCtx registers the Context handler for every common HTTP method
+func (rg *RouteGroup) AnyCtx(pattern string, handler enzovu.HandlerFunc, middlewares ...func(http.Handler) http.Handler) []*Route {
+	return rg.MatchCtx(anyMethods, pattern, handler, middlewares...)
+}
+
+// MatchCtx registers the Context handler for each of the given methods
+func (rg *RouteGroup) MatchCtx(methods []string, pattern string, handler enzovu.HandlerFunc, middlewares ...func(http.Handler) http.Handler) []*Route {
+	routes := make([]*Route, 0, len(methods))
+	for _, method := range methods {
+		routes = append(routes, rg.AddRouteCtx(strings.ToUpper(method), pattern, handler, middlewares...))
+	}
+	return routes
+}
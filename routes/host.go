@@ -0,0 +1,183 @@
+package routes
+
+import (
+	"fmt"
+	"net"
+	"net/http"
+	"strings"
+)
+
+// hostRoutes holds the routes registered for one host pattern
+type hostRoutes struct {
+	pattern string
+	labels  []token // one token per dot-separated label
+	port    string  // matched only when the pattern names a port
+	tree    *node
+}
+
+// Host returns a group whose routes only match requests for the given
+// host. Labels may be parameters, e.g. "{tenant}.example.com", and their
+// values are available through GetParams like path parameters. Without a
+// port in the pattern any port matches.
+//
+// Host groups are exclusive: a request whose host matches one is answered
+// from that group's routes only. Patterns with a port, then those with
+// fewer parameters, are tried first. The pattern "*" selects the fallback
+// group, which is the router's own routes and serves every other host.
+func (r *Router) Host(pattern string, middlewares ...func(http.Handler) http.Handler) *RouteGroup {
+	group := &RouteGroup{
+		router:      r,
+		middlewares: concatMiddlewares(nil, middlewares),
+	}
+	if pattern == "*" {
+		return group
+	}
+
+	for _, h := range r.hosts {
+		if h.pattern == pattern {
+			group.host = h
+			return group
+		}
+	}
+
+	r.mustNotBeFrozen("host group " + pattern + " added")
+	h, err := parseHost(pattern)
+	if err != nil {
+		r.fail(callerLocation(), "", "", err.Error())
+		// Strict mode: routes of the group go to a tree that is never served
+		group.host = &hostRoutes{pattern: pattern, tree: &node{}}
+		return group
+	}
+
+	// Keep the most specific patterns first so that admin.example.com
+	// wins over {tenant}.example.com regardless of registration order
+	i := len(r.hosts)
+	for i > 0 && h.specificity() > r.hosts[i-1].specificity() {
+		i--
+	}
+	r.hosts = append(r.hosts, nil)
+	copy(r.hosts[i+1:], r.hosts[i:])
+	r.hosts[i] = h
+
+	group.host = h
+	return group
+}
+
+func parseHost(pattern string) (*hostRoutes, error) {
+	h := &hostRoutes{pattern: pattern, tree: &node{}}
+
+	name := pattern
+	if i := strings.LastIndexByte(pattern, ':'); i >= 0 && !strings.Contains(pattern[i:], "}") {
+		name, h.port = pattern[:i], pattern[i+1:]
+	}
+	if name == "" {
+		return nil, fmt.Errorf("host pattern %q is empty", pattern)
+	}
+
+	for _, label := range strings.Split(name, ".") {
+		if !strings.HasPrefix(label, "{") {
+			if label == "" || strings.ContainsAny(label, "{}") {
+				return nil, fmt.Errorf("host pattern %q has an invalid label %q", pattern, label)
+			}
+			h.labels = append(h.labels, token{kind: staticNode, text: strings.ToLower(label)})
+			continue
+		}
+
+		if closingBrace(label, 0) != len(label)-1 {
+			return nil, fmt.Errorf("host pattern %q: parameter %s must span a whole label", pattern, label)
+		}
+		tok, err := parseParam(label[1 : len(label)-1])
+		if err != nil {
+			return nil, fmt.Errorf("host pattern %q: %w", pattern, err)
+		}
+		if tok.kind != paramNode || tok.optional {
+			return nil, fmt.Errorf("host pattern %q: parameter {%s} must be a plain parameter", pattern, tok.text)
+		}
+		h.labels = append(h.labels, tok)
+	}
+	return h, nil
+}
+
+// match reports whether host and port satisfy the pattern, appending the
+// host parameters to ps on success
+func (h *hostRoutes) match(host, port string, ps *[]Param) bool {
+	if h.port != "" && h.port != port {
+		return false
+	}
+
+	start := len(*ps)
+	for i, tok := range h.labels {
+		var label string
+		if i == len(h.labels)-1 {
+			label, host = host, ""
+		} else {
+			dot := strings.IndexByte(host, '.')
+			if dot < 0 {
+				*ps = (*ps)[:start]
+				return false
+			}
+			label, host = host[:dot], host[dot+1:]
+		}
+
+		if tok.kind == staticNode {
+			if label != tok.text {
+				*ps = (*ps)[:start]
+				return false
+			}
+			continue
+		}
+		if label == "" || !tok.constraint.matches(label) {
+			*ps = (*ps)[:start]
+			return false
+		}
+		*ps = append(*ps, Param{Key: tok.text, Value: label})
+	}
+	return true
+}
+
+// specificity ranks patterns naming a port, then those with fewer
+// parameter labels, ahead of the rest
+func (h *hostRoutes) specificity() int {
+	score := -len(h.paramNames())
+	if h.port != "" {
+		score += 1000
+	}
+	return score
+}
+
+func (h *hostRoutes) paramNames() []string {
+	var names []string
+	for _, tok := range h.labels {
+		if tok.kind == paramNode {
+			names = append(names, tok.text)
+		}
+	}
+	return names
+}
+
+// matchHost returns the host group serving req and its tree, recording
+// any host parameters in ps. The host is nil for the fallback group.
+func (r *Router) matchHost(req *http.Request, ps *[]Param) (*hostRoutes, *node) {
+	if len(r.hosts) == 0 {
+		return nil, r.tree
+	}
+
+	host, port := splitHostPort(req.Host)
+	for _, h := range r.hosts {
+		if h.match(host, port, ps) {
+			return h, h.tree
+		}
+	}
+	return nil, r.tree
+}
+
+// splitHostPort separates an optional port from a Host header value and
+// normalises the name to lower case without a trailing dot
+func splitHostPort(hostport string) (string, string) {
+	host, port := hostport, ""
+	if h, p, err := net.SplitHostPort(hostport); err == nil {
+		host, port = h, p
+	}
+	host = strings.TrimSuffix(strings.ToLower(host), ".")
+	return host, port
+}
@@ -0,0 +1,91 @@
+package routes
+
+import (
+	"fmt"
+	"reflect"
+	"runtime"
+	"strings"
+)
+
+// RouteInfo describes a registered route for tooling such as
+// `go-craft route:list`
+type RouteInfo struct {
+	Method      string   `json:"method"`
+	Host        string   `json:"host,omitempty"`
+	Pattern     string   `json:"pattern"`
+	Name        string   `json:"name,omitempty"`
+	Handler     string   `json:"handler"`
+	Middlewares []string `json:"middlewares"`
+}
+
+// Routes lists every registered route in registration order. Middlewares
+// holds the full chain, global middlewares first. A mounted *Router is
+// listed by its own routes below the mount prefix, other mounted handlers
+// as one route with the method "*".
+func (r *Router) Routes() []RouteInfo {
+	global := make([]string, 0, len(r.middlewares))
+	for _, mw := range r.middlewares {
+		global = append(global, funcName(mw))
+	}
+
+	infos := make([]RouteInfo, 0, len(r.routes))
+	for _, route := range r.routes {
+		chain := append([]string{}, global...)
+		if sub, ok := route.mounted.(*Router); ok {
+			chain = append(chain, route.MiddlewareNames...)
+			infos = append(infos, mountedRoutes(route, sub, chain)...)
+			continue
+		}
+		infos = append(infos, RouteInfo{
+			Method:      route.Method,
+			Host:        route.Host,
+			Pattern:     route.Pattern,
+			Name:        route.name,
+			Handler:     route.HandlerName,
+			Middlewares: append(chain, route.MiddlewareNames...),
+		})
+	}
+	return infos
+}
+
+// mountedRoutes lists the routes of sub as seen through its mount point
+func mountedRoutes(route *Route, sub *Router, chain []string) []RouteInfo {
+	infos := sub.Routes()
+	for i := range infos {
+		info := &infos[i]
+		if info.Pattern == "/" && route.mountPrefix != "" {
+			info.Pattern = route.mountPrefix
+		} else {
+			info.Pattern = route.mountPrefix + info.Pattern
+		}
+		if route.Host != "" {
+			info.Host = route.Host
+		}
+		info.Middlewares = append(append([]string{}, chain...), info.Middlewares...)
+	}
+	return infos
+}
+
+// handlerName identifies a handler for introspection: functions by name,
+// other http.Handlers by type
+func handlerName(handler interface{}) string {
+	if name := funcName(handler); name != "" {
+		return name
+	}
+	return fmt.Sprintf("%T", handler)
+}
+
+// funcName returns the qualified name of a function value, e.g.
+// "enzovu/app/Http/Controllers.(*UserController).Show"
+func funcName(fn interface{}) string {
+	v := reflect.ValueOf(fn)
+	if v.Kind() != reflect.Func || v.IsNil() {
+		return ""
+	}
+	f := runtime.FuncForPC(v.Pointer())
+	if f == nil {
+		return ""
+	}
+	// Method values are compiled as wrappers with a -fm suffix
+	return strings.TrimSuffix(f.Name(), "-fm")
+}
@@ -0,0 +1,83 @@
+package routes
+
+import (
+	"context"
+	"net/http"
+	"strings"
+)
+
+// anyMethod keys routes that serve every method, such as mount points.
+// Routes registered for the request's own method win over them.
+const anyMethod = "*"
+
+// mountParam captures the path below a mount point
+const mountParam = "mount"
+
+// Mount serves every request at or below prefix with handler, whatever
+// its method. The prefix is stripped, so a handler mounted at "/admin"
+// sees "/admin/users" as "/users". Global middlewares run before the
+// handler, and the routes of a mounted *Router are listed by Routes.
+//
+//	router.Mount("/admin", adminRouter)
+//	router.Mount("/gateway", gatewayMux, middleware.AuthMiddleware)
+func (r *Router) Mount(prefix string, handler http.Handler, middlewares ...func(http.Handler) http.Handler) {
+	r.Group("").Mount(prefix, handler, middlewares...)
+}
+
+// Mount serves every request below the group's prefix plus prefix with
+// handler, see Router.Mount
+func (rg *RouteGroup) Mount(prefix string, handler http.Handler, middlewares ...func(http.Handler) http.Handler) {
+	prefix = strings.TrimSuffix(rg.prefix+prefix, "/")
+	r := rg.router
+
+	route := r.addRoute(rg.host, anyMethod, prefix+"/{"+mountParam+"...}", mountHandler(handler), handlerName(handler), concatMiddlewares(rg.middlewares, middlewares))
+	route.conditions = rg.conditions
+	route.mounted = handler
+	route.mountPrefix = prefix
+
+	// The prefix itself is served as "/" of the mounted handler
+	if prefix != "" && route.tokens != nil {
+		bare := append([]token(nil), route.tokens[:len(route.tokens)-1]...)
+		last := &bare[len(bare)-1]
+		if last.kind == staticNode && len(last.text) > 1 {
+			last.text = strings.TrimSuffix(last.text, "/")
+		} else if last.kind == staticNode {
+			bare = bare[:len(bare)-1]
+		}
+
+		tree := r.tree
+		if rg.host != nil {
+			tree = rg.host.tree
+		}
+		tree.insert(bare).add(anyMethod, route)
+	}
+}
+
+// mountHandler strips the mount prefix from the request path before
+// calling handler
+func mountHandler(handler http.Handler) http.HandlerFunc {
+	return func(w http.ResponseWriter, req *http.Request) {
+		params := GetParams(req)
+		rest := params[mountParam]
+
+		prefix := strings.TrimSuffix(strings.TrimSuffix(req.URL.Path, rest), "/")
+		u := *req.URL
+		u.Path = "/" + rest
+		u.RawPath = ""
+		if raw, ok := strings.CutPrefix(req.URL.RawPath, prefix); ok && req.URL.RawPath != "" {
+			u.RawPath = "/" + strings.TrimPrefix(raw, "/")
+		}
+
+		// Keep the parameters of the prefix, without the mount's own
+		kept := make(map[string]string, len(params))
+		for key, value := range params {
+			if key != mountParam {
+				kept[key] = value
+			}
+		}
+
+		req = req.WithContext(context.WithValue(req.Context(), ParamsKey, kept))
+		req.URL = &u
+		handler.ServeHTTP(w, req)
+	}
+}
@@ -0,0 +1,173 @@
+package routes
+
+import (
+	"net/http"
+	"reflect"
+	"strings"
+
+	"enzovu/enzovu"
+)
+
+type resourceAction struct {
+	name    string
+	method  string // controller method implementing the action
+	methods []string
+	path    string
+	webOnly bool
+}
+
+// resourceActions lists the conventional routes in registration order,
+// matching the methods that `go-craft create controller` generates plus
+// the optional form pages. The member routes use {id}, which is what
+// generated controllers read.
+var resourceActions = []resourceAction{
+	{"index", "Index", []string{"GET"}, "", false},
+	{"new", "New", []string{"GET"}, "/create", true},
+	{"create", "Create", []string{"POST"}, "", false},
+	{"show", "Show", []string{"GET"}, "/{id}", false},
+	{"edit", "Edit", []string{"GET"}, "/{id}/edit", true},
+	{"update", "Update", []string{"PUT", "PATCH"}, "/{id}", false},
+	{"delete", "Delete", []string{"DELETE"}, "/{id}", false},
+}
+
+// controllerAction returns the controller method implementing action as
+// a handler, adapting func(*enzovu.Context) error methods, with its
+// qualified name. The handler is nil when the controller has no such
+// method or it has another signature.
+func (r *Router) controllerAction(controller interface{}, action resourceAction) (http.HandlerFunc, string) {
+	if controller == nil {
+		return nil, ""
+	}
+	method, ok := reflect.TypeOf(controller).MethodByName(action.method)
+	if !ok {
+		return nil, ""
+	}
+
+	name := funcName(method.Func.Interface())
+	switch h := reflect.ValueOf(controller).Method(method.Index).Interface().(type) {
+	case func(http.ResponseWriter, *http.Request):
+		return h, name
+	case func(*enzovu.Context) error:
+		return r.contextHandler(h), name
+	}
+	return nil, ""
+}
+
+type resourceOptions struct {
+	only   map[string]bool
+	except map[string]bool
+}
+
+// ResourceOption limits the routes registered by Resource and APIResource
+type ResourceOption func(*resourceOptions)
+
+// Only registers just the named actions, e.g. Only("index", "show")
+func Only(actions ...string) ResourceOption {
+	return func(o *resourceOptions) {
+		o.only = actionSet(actions)
+	}
+}
+
+// Except registers every action but the named ones
+func Except(actions ...string) ResourceOption {
+	return func(o *resourceOptions) {
+		o.except = actionSet(actions)
+	}
+}
+
+func actionSet(actions []string) map[string]bool {
+	set := make(map[string]bool, len(actions))
+	for _, action := range actions {
+		known := false
+		for _, a := range resourceActions {
+			known = known || a.name == action
+		}
+		if !known {
+			panic("routes: unknown resource action " + action)
+		}
+		set[action] = true
+	}
+	return set
+}
+
+// Resource registers the conventional routes for a controller:
+//
+//	GET       /photos            Index   photos.index
+//	GET       /photos/create     New     photos.new
+//	POST      /photos            Create  photos.create
+//	GET       /photos/{id}       Show    photos.show
+//	GET       /photos/{id}/edit  Edit    photos.edit
+//	PUT/PATCH /photos/{id}       Update  photos.update
+//	DELETE    /photos/{id}       Delete  photos.delete
+//
+// Only the actions the controller implements are registered, as plain
+// handlers or as func(*enzovu.Context) error. Nested
+// resources are declared with the parent parameter in the pattern, e.g.
+// "/posts/{post}/comments", and are named posts.comments.*. On a group
+// the names start with the group's path, e.g. admin.posts.* below
+// "/admin".
+func (r *Router) Resource(pattern string, controller interface{}, options ...ResourceOption) []*Route {
+	return r.Group("").Resource(pattern, controller, options...)
+}
+
+// APIResource is like Resource without the New and Edit form pages
+func (r *Router) APIResource(pattern string, controller interface{}, options ...ResourceOption) []*Route {
+	return r.Group("").APIResource(pattern, controller, options...)
+}
+
+// Resource registers the conventional controller routes below the group
+func (rg *RouteGroup) Resource(pattern string, controller interface{}, options ...ResourceOption) []*Route {
+	return rg.resource(pattern, controller, false, options)
+}
+
+// APIResource registers the API controller routes below the group
+func (rg *RouteGroup) APIResource(pattern string, controller interface{}, options ...ResourceOption) []*Route {
+	return rg.resource(pattern, controller, true, options)
+}
+
+func (rg *RouteGroup) resource(pattern string, controller interface{}, api bool, options []ResourceOption) []*Route {
+	var opts resourceOptions
+	for _, option := range options {
+		option(&opts)
+	}
+
+	pattern = strings.TrimSuffix(pattern, "/")
+	// Names include the group path, so admin and api groups can both
+	// register a posts resource
+	prefix := resourceName(rg.prefix + pattern)
+
+	var routes []*Route
+	for _, action := range resourceActions {
+		if api && action.webOnly {
+			continue
+		}
+		if opts.only != nil && !opts.only[action.name] || opts.except[action.name] {
+			continue
+		}
+		handler, name := rg.router.controllerAction(controller, action)
+		if handler == nil {
+			continue
+		}
+
+		for i, method := range action.methods {
+			route := rg.addRoute(method, pattern+action.path, handler, name, nil)
+			if i == 0 {
+				route.Name(prefix + "." + action.name)
+			}
+			routes = append(routes, route)
+		}
+	}
+	return routes
+}
+
+// resourceName turns "/posts/{post}/comments" into "posts.comments"
+func resourceName(pattern string) string {
+	var parts []string
+	for _, segment := range strings.Split(pattern, "/") {
+		if segment == "" || strings.HasPrefix(segment, "{") {
+			continue
+		}
+		parts = append(parts, segment)
+	}
+	return strings.Join(parts, ".")
+}
@@ -2,23 +2,101 @@ package routes
 
 import (
 	"context"
+	"fmt"
 	"net/http"
-	"regexp"
+	"sort"
+	"strconv"
 	"strings"
+	"sync"
+	"sync/atomic"
+
+	"enzovu/enzovu"
 )
 
 type Route struct {
 	Method      string
+	Host        string
 	Pattern     string
 	Handler     http.HandlerFunc
-	Regex       *regexp.Regexp
 	ParamNames  []string
 	Middlewares []func(http.Handler) http.Handler
+
+	// HandlerName and MiddlewareNames identify the registered functions
+	// for introspection, see Router.Routes
+	HandlerName     string
+	MiddlewareNames []string
+
+	name       string
+	location   string // file:line of the registering call
+	tokens     []token
+	router     *Router
+	conditions []condition
+	hostParams []string // parameters of Host, which URL leaves out
+
+	// mounted is the handler served below mountPrefix, see Router.Mount
+	mounted     http.Handler
+	mountPrefix string
+
+	// chain and headChain are the composed handlers, global middlewares
+	// included, built once by Router.compile
+	chain     http.Handler
+	headChain http.Handler
 }
 
 type Router struct {
-	routes      []Route
+	// HandleMethodNotAllowed answers 405 with an Allow header when the path
+	// is registered under other methods, instead of 404
+	HandleMethodNotAllowed bool
+
+	// HandleOPTIONS answers OPTIONS requests from the registered methods
+	// unless an explicit OPTIONS route exists
+	HandleOPTIONS bool
+
+	// HandleHEAD serves HEAD requests through the GET handler and discards
+	// the body unless an explicit HEAD route exists
+	HandleHEAD bool
+
+	// GlobalOPTIONS, if set, writes automatic OPTIONS responses. The Allow
+	// header is already set when it runs.
+	GlobalOPTIONS http.Handler
+
+	// NotFound, if set, replaces http.NotFound for unmatched requests. It
+	// is composed with the global middlewares by Freeze.
+	NotFound http.Handler
+
+	// MethodNotAllowed, if set, writes 405 responses. The Allow header is
+	// already set when it runs. It is composed by Freeze, like NotFound.
+	MethodNotAllowed http.Handler
+
+	// PanicHandler, if set, recovers panics raised while serving a request
+	// and writes the response. The third argument is the recovered value.
+	PanicHandler func(http.ResponseWriter, *http.Request, interface{})
+
+	// ErrorHandler, if set, replaces enzovu.DefaultErrorHandler for errors
+	// returned by enzovu.HandlerFunc routes
+	ErrorHandler enzovu.ErrorHandler
+
+	// Strict collects invalid patterns for Validate instead of panicking,
+	// so that startup can fail with an error listing every problem
+	Strict bool
+
+	routes      []*Route
+	named       map[string]*Route
+	tree        *node
+	hosts       []*hostRoutes
+	scopes      []*errorScope
+	bindings    map[string]Resolver
+	maxParams   int
+	paramsPool  sync.Pool
 	middlewares []func(http.Handler) http.Handler
+	problems    RouteErrors
+	frozen      atomic.Bool
+	freezeOnce  sync.Once
+
+	// Fallback chains, global middlewares included, built by Freeze
+	notFoundChain         http.Handler
+	methodNotAllowedChain http.Handler
+	optionsChain          http.Handler
 }
 
 type contextKey string
@@ -26,98 +104,367 @@ type contextKey string
 const ParamsKey contextKey = "params"
 
 func NewRouter() *Router {
-	return &Router{}
+	return &Router{
+		HandleMethodNotAllowed: true,
+		HandleOPTIONS:          true,
+		HandleHEAD:             true,
+	}
 }
 
-// Use adds middleware to all routes
+// Use adds middleware to all routes, including those registered before
+// the call. Chains are composed once when the router is frozen, so Use
+// panics after that.
 func (r *Router) Use(middleware func(http.Handler) http.Handler) {
+	if r.frozen.Load() {
+		panic("routes: Use called after the router was frozen; add global middleware before serving requests")
+	}
 	r.middlewares = append(r.middlewares, middleware)
 }
 
-func (r *Router) AddRoute(method, pattern string, handler http.HandlerFunc, middlewares ...func(http.Handler) http.Handler) {
-	// Extract parameter names and convert pattern to regex
-	paramNames := []string{}
-	regexPattern := "^" + pattern + "$"
+// Freeze composes the middleware chain of every route, and of the 404,
+// 405 and OPTIONS responses, so that requests no longer build them.
+// ServeHTTP freezes the router on its first request. The routing table
+// is read without locks from then on, so registering routes, groups,
+// error handlers or middleware afterwards panics.
+func (r *Router) Freeze() {
+	r.freezeOnce.Do(func() {
+		r.frozen.Store(true)
+		for _, route := range r.routes {
+			r.compile(route)
+		}
+		r.compileFallbacks()
+	})
+}
 
-	// Find all {param} patterns
-	paramRegex := regexp.MustCompile(`\{([^}]+)\}`)
-	matches := paramRegex.FindAllStringSubmatch(pattern, -1)
+// mustNotBeFrozen panics when the routing table is changed after Freeze
+func (r *Router) mustNotBeFrozen(what string) {
+	if r.frozen.Load() {
+		panic("routes: " + what + " after the router was frozen; register routes before serving requests")
+	}
+}
 
-	for _, match := range matches {
-		paramNames = append(paramNames, match[1])
-		// Replace {param} with capture group
-		regexPattern = strings.Replace(regexPattern, match[0], "([^/]+)", 1)
+// compile builds the full chain of route: global middlewares, model
+// binding, route middlewares, then the handler
+func (r *Router) compile(route *Route) {
+	next := r.bindingHandler(route, route.handler())
+	route.chain = r.wrap(next)
+	if route.Method == http.MethodGet {
+		route.headChain = r.wrap(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+			next.ServeHTTP(&headResponseWriter{ResponseWriter: w}, req)
+		}))
 	}
+}
 
-	regex := regexp.MustCompile(regexPattern)
+// compileFallbacks builds the chains of the automatic responses, for the
+// router and for every group that overrides them
+func (r *Router) compileFallbacks() {
+	r.notFoundChain = r.wrap(r.notFoundHandler(nil))
+	r.methodNotAllowedChain = r.wrap(r.methodNotAllowedHandler(nil))
+	r.optionsChain = r.wrap(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		if r.GlobalOPTIONS != nil {
+			r.GlobalOPTIONS.ServeHTTP(w, req)
+			return
+		}
+		w.WriteHeader(http.StatusNoContent)
+	}))
+	for _, scope := range r.scopes {
+		if scope.notFound != nil {
+			scope.notFoundChain = r.wrap(scope.notFound)
+		}
+		if scope.methodNotAllowed != nil {
+			scope.methodNotAllowedChain = r.wrap(scope.methodNotAllowed)
+		}
+	}
+}
 
-	route := Route{
+// wrap applies the global middlewares to handler
+func (r *Router) wrap(handler http.Handler) http.Handler {
+	for i := len(r.middlewares) - 1; i >= 0; i-- {
+		handler = r.middlewares[i](handler)
+	}
+	return handler
+}
+
+func (r *Router) AddRoute(method, pattern string, handler http.HandlerFunc, middlewares ...func(http.Handler) http.Handler) *Route {
+	return r.addRoute(nil, method, pattern, handler, handlerName(handler), middlewares)
+}
+
+// addRoute registers a route in the tree of host, or in the router's own
+// tree when host is nil. name identifies the handler for Routes.
+func (r *Router) addRoute(host *hostRoutes, method, pattern string, handler http.HandlerFunc, name string, middlewares []func(http.Handler) http.Handler) *Route {
+	r.mustNotBeFrozen(fmt.Sprintf("route %s %s registered", method, pattern))
+	location := callerLocation()
+	tokens, err := parsePattern(pattern)
+	if err != nil {
+		r.fail(location, method, pattern, err.Error())
+		// Strict mode: hand back a route that is never served
+		return &Route{Method: method, Pattern: pattern, location: location, router: r}
+	}
+	if handler == nil {
+		r.fail(location, method, pattern, "handler is nil")
+		return &Route{Method: method, Pattern: pattern, location: location, router: r}
+	}
+
+	paramNames := []string{}
+	for _, tok := range tokens {
+		if tok.kind != staticNode {
+			paramNames = append(paramNames, tok.text)
+		}
+	}
+
+	route := &Route{
 		Method:      method,
 		Pattern:     pattern,
 		Handler:     handler,
-		Regex:       regex,
 		ParamNames:  paramNames,
-		Middlewares: middlewares,
+		Middlewares: concatMiddlewares(nil, middlewares),
+		HandlerName: name,
+		location:    location,
+		tokens:      tokens,
+		router:      r,
+	}
+	for _, mw := range middlewares {
+		route.MiddlewareNames = append(route.MiddlewareNames, funcName(mw))
 	}
 	r.routes = append(r.routes, route)
+
+	if r.tree == nil {
+		r.tree = &node{}
+	}
+	tree := r.tree
+	if host != nil {
+		route.Host = host.pattern
+		route.hostParams = host.paramNames()
+		tree = host.tree
+		for _, name := range host.paramNames() {
+			if route.hasParam(name) {
+				r.fail(location, method, pattern, fmt.Sprintf("parameter {%s} is also a parameter of host %q", name, host.pattern))
+			}
+		}
+		paramNames = append(paramNames, host.paramNames()...)
+	}
+
+	// Optional parameters register one leaf per shorter variant
+	for _, variant := range expandOptional(tokens) {
+		// The first registration of a method and pattern wins, as before,
+		// unless route conditions such as API versions tell them apart
+		tree.insert(variant).add(method, route)
+	}
+
+	if len(paramNames) > r.maxParams {
+		r.maxParams = len(paramNames)
+	}
+	return route
 }
 
 func (r *Router) ServeHTTP(w http.ResponseWriter, req *http.Request) {
-	for _, route := range r.routes {
-		if route.Method == req.Method && route.Regex.MatchString(req.URL.Path) {
-			// Extract parameters
-			matches := route.Regex.FindStringSubmatch(req.URL.Path)
-			params := make(map[string]string)
-
-			for i, name := range route.ParamNames {
-				if i+1 < len(matches) {
-					params[name] = matches[i+1]
-				}
-			}
+	r.Freeze()
 
-			// Add params to request context
-			ctx := context.WithValue(req.Context(), ParamsKey, params)
-			req = req.WithContext(ctx)
+	if r.recovers() {
+		defer r.recoverPanic(w, req)
+	}
 
-			// Build handler chain with middlewares
-			handler := http.Handler(route.Handler)
+	ps := r.getParams()
+	defer r.putParams(ps)
 
-			// Apply route-specific middlewares
-			for i := len(route.Middlewares) - 1; i >= 0; i-- {
-				handler = route.Middlewares[i](handler)
-			}
+	handler, req := r.match(w, req, ps)
+	handler.ServeHTTP(w, req)
+}
 
-			// Apply global middlewares
-			for i := len(r.middlewares) - 1; i >= 0; i-- {
-				handler = r.middlewares[i](handler)
-			}
+// match resolves the handler for req, global middlewares included,
+// falling back to the automatic HEAD, OPTIONS, 405 and 404 responses
+func (r *Router) match(w http.ResponseWriter, req *http.Request, ps *[]Param) (http.Handler, *http.Request) {
+	host, tree := r.matchHost(req, ps)
+	if tree == nil {
+		return r.notFoundChainFor(host, req.URL.Path), req
+	}
+	path := req.URL.Path
 
-			handler.ServeHTTP(w, req)
-			return
+	if route := tree.lookup(path, req.Method, req, ps); route != nil {
+		return route.chain, withParams(req, *ps)
+	}
+
+	if req.Method == http.MethodHead && r.HandleHEAD {
+		if route := tree.lookup(path, http.MethodGet, req, ps); route != nil {
+			return route.headChain, withParams(req, *ps)
+		}
+	}
+
+	return r.fallback(w, host, tree, req), req
+}
+
+// fallback returns the precomposed OPTIONS, 405 or 404 chain for a
+// request no route matched, setting the Allow header for the first two
+func (r *Router) fallback(w http.ResponseWriter, host *hostRoutes, tree *node, req *http.Request) http.Handler {
+	path := req.URL.Path
+
+	if req.Method == http.MethodOptions && r.HandleOPTIONS {
+		if allow := r.allowed(tree, req); allow != "" {
+			w.Header().Set("Allow", allow)
+			return r.optionsChain
 		}
 	}
-	http.NotFound(w, req)
+
+	if r.HandleMethodNotAllowed {
+		if allow := r.allowed(tree, req); allow != "" {
+			w.Header().Set("Allow", allow)
+			return r.methodNotAllowedChainFor(host, path)
+		}
+	}
+
+	return r.notFoundChainFor(host, path)
+}
+
+// accepts reports whether every condition of the route holds for req
+func (route *Route) accepts(req *http.Request) bool {
+	for _, condition := range route.conditions {
+		if !condition.match(req) {
+			return false
+		}
+	}
+	return true
+}
+
+// handler builds the route handler wrapped in its own middlewares
+func (route *Route) handler() http.Handler {
+	handler := http.Handler(route.Handler)
+	for i := len(route.Middlewares) - 1; i >= 0; i-- {
+		handler = route.Middlewares[i](handler)
+	}
+	return handler
+}
+
+// withParams adds the captured params to the request context, keeping
+// those of a router the request was mounted from
+func withParams(req *http.Request, ps []Param) *http.Request {
+	if len(ps) == 0 {
+		return req
+	}
+	parent, _ := req.Context().Value(ParamsKey).(map[string]string)
+	params := make(map[string]string, len(ps)+len(parent))
+	for key, value := range parent {
+		params[key] = value
+	}
+	for _, p := range ps {
+		params[p.Key] = p.Value
+	}
+	ctx := context.WithValue(req.Context(), ParamsKey, params)
+	return req.WithContext(ctx)
+}
+
+// allowed returns the Allow header value for req's path in tree, or "" if
+// no route matches it under any method. A path of "*" lists every method
+// registered on the router.
+func (r *Router) allowed(tree *node, req *http.Request) string {
+	path := req.URL.Path
+	methods := make(map[string]bool)
+	if path == "*" {
+		for _, route := range r.routes {
+			if route.Method != anyMethod {
+				methods[route.Method] = true
+			}
+		}
+	} else {
+		tree.collectMethods(path, req, methods)
+	}
+	if len(methods) == 0 {
+		return ""
+	}
+
+	if methods[http.MethodGet] && r.HandleHEAD {
+		methods[http.MethodHead] = true
+	}
+	if r.HandleOPTIONS {
+		methods[http.MethodOptions] = true
+	}
+
+	allow := make([]string, 0, len(methods))
+	for method := range methods {
+		allow = append(allow, method)
+	}
+	sort.Strings(allow)
+	return strings.Join(allow, ", ")
+}
+
+// headResponseWriter discards the body written by a GET handler serving HEAD
+type headResponseWriter struct {
+	http.ResponseWriter
+}
+
+func (hw *headResponseWriter) Write(b []byte) (int, error) {
+	return len(b), nil
+}
+
+func (hw *headResponseWriter) Flush() {
+	hw.FlushError()
+}
+
+func (hw *headResponseWriter) FlushError() error {
+	return http.NewResponseController(hw.ResponseWriter).Flush()
+}
+
+func (hw *headResponseWriter) Unwrap() http.ResponseWriter {
+	return hw.ResponseWriter
+}
+
+// getParams borrows a parameter buffer large enough for any registered route
+func (r *Router) getParams() *[]Param {
+	if ps, ok := r.paramsPool.Get().(*[]Param); ok {
+		*ps = (*ps)[:0]
+		return ps
+	}
+	ps := make([]Param, 0, r.maxParams)
+	return &ps
+}
+
+func (r *Router) putParams(ps *[]Param) {
+	r.paramsPool.Put(ps)
 }
 
 // Helper methods for HTTP verbs
-func (r *Router) GET(pattern string, handler http.HandlerFunc, middlewares ...func(http.Handler) http.Handler) {
-	r.AddRoute("GET", pattern, handler, middlewares...)
+func (r *Router) GET(pattern string, handler http.HandlerFunc, middlewares ...func(http.Handler) http.Handler) *Route {
+	return r.AddRoute("GET", pattern, handler, middlewares...)
+}
+
+func (r *Router) POST(pattern string, handler http.HandlerFunc, middlewares ...func(http.Handler) http.Handler) *Route {
+	return r.AddRoute("POST", pattern, handler, middlewares...)
+}
+
+func (r *Router) PUT(pattern string, handler http.HandlerFunc, middlewares ...func(http.Handler) http.Handler) *Route {
+	return r.AddRoute("PUT", pattern, handler, middlewares...)
+}
+
+func (r *Router) DELETE(pattern string, handler http.HandlerFunc, middlewares ...func(http.Handler) http.Handler) *Route {
+	return r.AddRoute("DELETE", pattern, handler, middlewares...)
 }
 
-func (r *Router) POST(pattern string, handler http.HandlerFunc, middlewares ...func(http.Handler) http.Handler) {
-	r.AddRoute("POST", pattern, handler, middlewares...)
+func (r *Router) PATCH(pattern string, handler http.HandlerFunc, middlewares ...func(http.Handler) http.Handler) *Route {
+	return r.AddRoute("PATCH", pattern, handler, middlewares...)
 }
 
-func (r *Router) PUT(pattern string, handler http.HandlerFunc, middlewares ...func(http.Handler) http.Handler) {
-	r.AddRoute("PUT", pattern, handler, middlewares...)
+func (r *Router) HEAD(pattern string, handler http.HandlerFunc, middlewares ...func(http.Handler) http.Handler) *Route {
+	return r.AddRoute("HEAD", pattern, handler, middlewares...)
 }
 
-func (r *Router) DELETE(pattern string, handler http.HandlerFunc, middlewares ...func(http.Handler) http.Handler) {
-	r.AddRoute("DELETE", pattern, handler, middlewares...)
+func (r *Router) OPTIONS(pattern string, handler http.HandlerFunc, middlewares ...func(http.Handler) http.Handler) *Route {
+	return r.AddRoute("OPTIONS", pattern, handler, middlewares...)
 }
 
-func (r *Router) PATCH(pattern string, handler http.HandlerFunc, middlewares ...func(http.Handler) http.Handler) {
-	r.AddRoute("PATCH", pattern, handler, middlewares...)
+// anyMethods are the methods registered by Any
+var anyMethods = []string{"GET", "POST", "PUT", "PATCH", "DELETE", "HEAD", "OPTIONS"}
+
+// Any registers the handler for every common HTTP method
+func (r *Router) Any(pattern string, handler http.HandlerFunc, middlewares ...func(http.Handler) http.Handler) []*Route {
+	return r.Match(anyMethods, pattern, handler, middlewares...)
+}
+
+// Match registers the handler for each of the given methods
+func (r *Router) Match(methods []string, pattern string, handler http.HandlerFunc, middlewares ...func(http.Handler) http.Handler) []*Route {
+	routes := make([]*Route, 0, len(methods))
+	for _, method := range methods {
+		routes = append(routes, r.AddRoute(strings.ToUpper(method), pattern, handler, middlewares...))
+	}
+	return routes
 }
 
 // Group allows grouping routes with common prefix and middlewares
@@ -125,34 +472,101 @@ func (r *Router) Group(prefix string, middlewares ...func(http.Handler) http.Han
 	return &RouteGroup{
 		router:      r,
 		prefix:      prefix,
-		middlewares: middlewares,
+		middlewares: concatMiddlewares(nil, middlewares),
 	}
 }
 
+// RouteGroup registers routes under a shared prefix and middleware chain.
+// Groups nest to any depth, each level adding to its parent's prefix and
+// middlewares.
 type RouteGroup struct {
 	router      *Router
+	host        *hostRoutes
 	prefix      string
 	middlewares []func(http.Handler) http.Handler
+	conditions  []condition
+}
+
+// Group creates a nested group below rg
+func (rg *RouteGroup) Group(prefix string, middlewares ...func(http.Handler) http.Handler) *RouteGroup {
+	return &RouteGroup{
+		router:      rg.router,
+		host:        rg.host,
+		prefix:      rg.prefix + prefix,
+		middlewares: concatMiddlewares(rg.middlewares, middlewares),
+		conditions:  rg.conditions,
+	}
+}
+
+// Use adds middleware to routes registered on the group, and on groups
+// nested in it, from now on
+func (rg *RouteGroup) Use(middlewares ...func(http.Handler) http.Handler) {
+	rg.middlewares = concatMiddlewares(rg.middlewares, middlewares)
+}
+
+// AddRoute registers a route below the group's prefix
+func (rg *RouteGroup) AddRoute(method, pattern string, handler http.HandlerFunc, middlewares ...func(http.Handler) http.Handler) *Route {
+	return rg.addRoute(method, pattern, handler, handlerName(handler), middlewares)
+}
+
+func (rg *RouteGroup) addRoute(method, pattern string, handler http.HandlerFunc, name string, middlewares []func(http.Handler) http.Handler) *Route {
+	allMiddlewares := concatMiddlewares(rg.middlewares, middlewares)
+	route := rg.router.addRoute(rg.host, method, rg.prefix+pattern, handler, name, allMiddlewares)
+	route.conditions = rg.conditions
+	return route
+}
+
+func (rg *RouteGroup) GET(pattern string, handler http.HandlerFunc, middlewares ...func(http.Handler) http.Handler) *Route {
+	return rg.AddRoute("GET", pattern, handler, middlewares...)
+}
+
+func (rg *RouteGroup) POST(pattern string, handler http.HandlerFunc, middlewares ...func(http.Handler) http.Handler) *Route {
+	return rg.AddRoute("POST", pattern, handler, middlewares...)
+}
+
+func (rg *RouteGroup) PUT(pattern string, handler http.HandlerFunc, middlewares ...func(http.Handler) http.Handler) *Route {
+	return rg.AddRoute("PUT", pattern, handler, middlewares...)
+}
+
+func (rg *RouteGroup) DELETE(pattern string, handler http.HandlerFunc, middlewares ...func(http.Handler) http.Handler) *Route {
+	return rg.AddRoute("DELETE", pattern, handler, middlewares...)
 }
 
-func (rg *RouteGroup) GET(pattern string, handler http.HandlerFunc, middlewares ...func(http.Handler) http.Handler) {
-	allMiddlewares := append(rg.middlewares, middlewares...)
-	rg.router.AddRoute("GET", rg.prefix+pattern, handler, allMiddlewares...)
+func (rg *RouteGroup) PATCH(pattern string, handler http.HandlerFunc, middlewares ...func(http.Handler) http.Handler) *Route {
+	return rg.AddRoute("PATCH", pattern, handler, middlewares...)
 }
 
-func (rg *RouteGroup) POST(pattern string, handler http.HandlerFunc, middlewares ...func(http.Handler) http.Handler) {
-	allMiddlewares := append(rg.middlewares, middlewares...)
-	rg.router.AddRoute("POST", rg.prefix+pattern, handler, allMiddlewares...)
+func (rg *RouteGroup) HEAD(pattern string, handler http.HandlerFunc, middlewares ...func(http.Handler) http.Handler) *Route {
+	return rg.AddRoute("HEAD", pattern, handler, middlewares...)
 }
 
-func (rg *RouteGroup) PUT(pattern string, handler http.HandlerFunc, middlewares ...func(http.Handler) http.Handler) {
-	allMiddlewares := append(rg.middlewares, middlewares...)
-	rg.router.AddRoute("PUT", rg.prefix+pattern, handler, allMiddlewares...)
+func (rg *RouteGroup) OPTIONS(pattern string, handler http.HandlerFunc, middlewares ...func(http.Handler) http.Handler) *Route {
+	return rg.AddRoute("OPTIONS", pattern, handler, middlewares...)
 }
 
-func (rg *RouteGroup) DELETE(pattern string, handler http.HandlerFunc, middlewares ...func(http.Handler) http.Handler) {
-	allMiddlewares := append(rg.middlewares, middlewares...)
-	rg.router.AddRoute("DELETE", rg.prefix+pattern, handler, allMiddlewares...)
+// Any registers the handler for every common HTTP method
+func (rg *RouteGroup) Any(pattern string, handler http.HandlerFunc, middlewares ...func(http.Handler) http.Handler) []*Route {
+	return rg.Match(anyMethods, pattern, handler, middlewares...)
+}
+
+// Match registers the handler for each of the given methods
+func (rg *RouteGroup) Match(methods []string, pattern string, handler http.HandlerFunc, middlewares ...func(http.Handler) http.Handler) []*Route {
+	routes := make([]*Route, 0, len(methods))
+	for _, method := range methods {
+		routes = append(routes, rg.AddRoute(strings.ToUpper(method), pattern, handler, middlewares...))
+	}
+	return routes
+}
+
+// concatMiddlewares returns a new slice holding a followed by b, so that
+// routes and groups never share a backing array
+func concatMiddlewares(a, b []func(http.Handler) http.Handler) []func(http.Handler) http.Handler {
+	if len(a)+len(b) == 0 {
+		return nil
+	}
+	all := make([]func(http.Handler) http.Handler, 0, len(a)+len(b))
+	all = append(all, a...)
+	return append(all, b...)
 }
 
 // Helper function to get parameters from request context
@@ -169,11 +583,26 @@ func GetParam(r *http.Request, name string) string {
 	return params[name]
 }
 
-// Static file serving
-func (r *Router) Static(prefix, dir string) {
-	fileServer := http.FileServer(http.Dir(dir))
-	r.GET(prefix+"/*", func(w http.ResponseWriter, req *http.Request) {
-		req.URL.Path = strings.TrimPrefix(req.URL.Path, prefix)
-		fileServer.ServeHTTP(w, req)
-	})
+// GetParamInt returns a parameter as an int. Parameters declared as
+// {name:int} have already been validated by the router.
+func GetParamInt(r *http.Request, name string) (int, error) {
+	value, ok := GetParams(r)[name]
+	if !ok {
+		return 0, fmt.Errorf("route parameter %q not set", name)
+	}
+	n, err := strconv.Atoi(value)
+	if err != nil {
+		return 0, fmt.Errorf("route parameter %q is not an integer: %w", name, err)
+	}
+	return n, nil
+}
+
+// GetParamUUID returns a parameter as a UUID. Parameters declared as
+// {name:uuid} have already been validated by the router.
+func GetParamUUID(r *http.Request, name string) (UUID, error) {
+	value, ok := GetParams(r)[name]
+	if !ok {
+		return UUID{}, fmt.Errorf("route parameter %q not set", name)
+	}
+	return ParseUUID(value)
 }
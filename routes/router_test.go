@@ -0,0 +1,144 @@
+package routes
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+// header returns a middleware that sets name on every response
+func header(name string) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+			w.Header().Set(name, "1")
+			next.ServeHTTP(w, req)
+		})
+	}
+}
+
+func TestFallbacksRunGlobalMiddleware(t *testing.T) {
+	router := NewRouter()
+	router.Use(header("X-Global"))
+	router.GET("/items/{id}", func(w http.ResponseWriter, req *http.Request) {})
+	api := router.Group("/api")
+	api.NotFound(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		w.WriteHeader(http.StatusTeapot)
+	}))
+
+	tests := []struct {
+		method, path string
+		status       int
+		allow        string
+	}{
+		{http.MethodGet, "/missing", http.StatusNotFound, ""},
+		{http.MethodGet, "/api/missing", http.StatusTeapot, ""},
+		{http.MethodPost, "/items/1", http.StatusMethodNotAllowed, "GET, HEAD, OPTIONS"},
+		{http.MethodOptions, "/items/1", http.StatusNoContent, "GET, HEAD, OPTIONS"},
+	}
+	for _, tt := range tests {
+		rec := httptest.NewRecorder()
+		router.ServeHTTP(rec, httptest.NewRequest(tt.method, tt.path, nil))
+
+		if rec.Code != tt.status {
+			t.Errorf("%s %s: status %d, want %d", tt.method, tt.path, rec.Code, tt.status)
+		}
+		if got := rec.Header().Get("Allow"); got != tt.allow {
+			t.Errorf("%s %s: Allow %q, want %q", tt.method, tt.path, got, tt.allow)
+		}
+		if rec.Header().Get("X-Global") == "" {
+			t.Errorf("%s %s: global middleware did not run", tt.method, tt.path)
+		}
+	}
+}
+
+func TestRegisterAfterFreezePanics(t *testing.T) {
+	tests := map[string]func(*Router){
+		"route":      func(r *Router) { r.GET("/late", func(w http.ResponseWriter, req *http.Request) {}) },
+		"middleware": func(r *Router) { r.Use(header("X-Late")) },
+		"host group": func(r *Router) { r.Host("late.example.com") },
+		"not found":  func(r *Router) { r.Group("/late").NotFound(http.NotFoundHandler()) },
+	}
+	for name, register := range tests {
+		t.Run(name, func(t *testing.T) {
+			router := NewRouter()
+			router.Freeze()
+			defer func() {
+				if recover() == nil {
+					t.Errorf("registering a %s after Freeze did not panic", name)
+				}
+			}()
+			register(router)
+		})
+	}
+}
+
+// benchRoutes is the routing table shared by the ServeHTTP benchmarks
+func benchRoutes() *Router {
+	router := NewRouter()
+	router.Use(header("X-One"))
+	router.Use(header("X-Two"))
+	ok := func(w http.ResponseWriter, req *http.Request) {}
+	router.GET("/", ok)
+	router.GET("/users/{id:int}", ok, header("X-Route"))
+	router.GET("/users/{id:int}/posts/{post}", ok)
+	router.POST("/users", ok)
+	return router
+}
+
+// benchServe runs serve once per iteration for each benchmarked request
+func benchServe(b *testing.B, serve func(http.ResponseWriter, *http.Request)) {
+	for _, bench := range []struct{ name, method, path string }{
+		{"static", http.MethodGet, "/"},
+		{"param", http.MethodGet, "/users/42"},
+		{"params", http.MethodGet, "/users/42/posts/hello"},
+		{"not found", http.MethodGet, "/missing"},
+		{"method not allowed", http.MethodDelete, "/users"},
+	} {
+		b.Run(bench.name, func(b *testing.B) {
+			req := httptest.NewRequest(bench.method, bench.path, nil)
+			w := httptest.NewRecorder()
+			serve(w, req)
+
+			b.ReportAllocs()
+			b.ResetTimer()
+			for i := 0; i < b.N; i++ {
+				for key := range w.HeaderMap {
+					delete(w.HeaderMap, key)
+				}
+				serve(w, req)
+			}
+		})
+	}
+}
+
+// BenchmarkServeHTTP reports the allocations per request through a
+// frozen router with a middleware chain
+func BenchmarkServeHTTP(b *testing.B) {
+	router := benchRoutes()
+	benchServe(b, router.ServeHTTP)
+}
+
+// BenchmarkServeHTTPComposedPerRequest is the baseline for
+// BenchmarkServeHTTP: it serves the same requests the way ServeHTTP did
+// before Freeze, composing the route and global middlewares per request
+func BenchmarkServeHTTPComposedPerRequest(b *testing.B) {
+	router := benchRoutes()
+	router.Freeze()
+	benchServe(b, func(w http.ResponseWriter, req *http.Request) {
+		ps := router.getParams()
+		defer router.putParams(ps)
+
+		var handler http.Handler
+		_, tree := router.matchHost(req, ps)
+		if route := tree.lookup(req.URL.Path, req.Method, req, ps); route != nil {
+			handler = router.bindingHandler(route, route.handler())
+			req = withParams(req, *ps)
+		} else if allow := router.allowed(tree, req); allow != "" {
+			w.Header().Set("Allow", allow)
+			handler = router.methodNotAllowedHandler(nil)
+		} else {
+			handler = router.notFoundHandler(nil)
+		}
+		router.wrap(handler).ServeHTTP(w, req)
+	})
+}
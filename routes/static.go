@@ -0,0 +1,131 @@
+package routes
+
+import (
+	"net/http"
+	"os"
+	"path"
+	"strings"
+)
+
+// StaticConfig controls how StaticWithConfig serves a directory
+type StaticConfig struct {
+	// Root is the directory to serve, ignored when FS is set
+	Root string
+
+	// FS overrides Root, e.g. for embedded assets
+	FS http.FileSystem
+
+	// Index lists the files tried, in order, when a directory is requested
+	Index []string
+
+	// Browse enables directory listings when no index file exists
+	Browse bool
+
+	// SPAFallback is served for missing paths without a file extension,
+	// so client-side routes of a frontend build resolve to its entry page
+	SPAFallback string
+}
+
+// DefaultStaticConfig returns the configuration used by Static
+func DefaultStaticConfig(dir string) StaticConfig {
+	return StaticConfig{
+		Root:  dir,
+		Index: []string{"index.html"},
+	}
+}
+
+// Static serves files from dir under prefix, with index.html as the
+// directory index and directory listings turned off
+func (r *Router) Static(prefix, dir string) {
+	r.StaticWithConfig(prefix, DefaultStaticConfig(dir))
+}
+
+// StaticWithConfig serves files under prefix using a custom configuration.
+// The bare prefix is served too, redirecting to prefix + "/" like any
+// other directory.
+func (r *Router) StaticWithConfig(prefix string, config StaticConfig) {
+	prefix = strings.TrimSuffix(prefix, "/")
+	handler := staticHandler(config, r.serveNotFound)
+	if prefix != "" {
+		r.GET(prefix, handler)
+	}
+	r.GET(prefix+"/{filepath...}", handler)
+}
+
+func staticHandler(config StaticConfig, notFound http.HandlerFunc) http.HandlerFunc {
+	fs := config.FS
+	if fs == nil {
+		fs = http.Dir(config.Root)
+	}
+	browser := http.FileServer(fs)
+
+	return func(w http.ResponseWriter, req *http.Request) {
+		name := path.Clean("/" + GetParam(req, "filepath"))
+
+		f, err := fs.Open(name)
+		if err != nil {
+			if os.IsNotExist(err) && config.SPAFallback != "" && path.Ext(name) == "" &&
+				serveStaticFile(w, req, fs, path.Join("/", config.SPAFallback)) {
+				return
+			}
+			notFound(w, req)
+			return
+		}
+		defer f.Close()
+
+		info, err := f.Stat()
+		if err != nil {
+			notFound(w, req)
+			return
+		}
+		if !info.IsDir() {
+			http.ServeContent(w, req, info.Name(), info.ModTime(), f)
+			return
+		}
+
+		// Directories need a trailing slash so relative links resolve
+		if !strings.HasSuffix(req.URL.Path, "/") {
+			target := req.URL.Path + "/"
+			if req.URL.RawQuery != "" {
+				target += "?" + req.URL.RawQuery
+			}
+			http.Redirect(w, req, target, http.StatusMovedPermanently)
+			return
+		}
+
+		for _, index := range config.Index {
+			if serveStaticFile(w, req, fs, path.Join(name, index)) {
+				return
+			}
+		}
+
+		if config.Browse {
+			// FileServer expects the path relative to the served root
+			listing := *req
+			u := *req.URL
+			u.Path = strings.TrimSuffix(name, "/") + "/"
+			listing.URL = &u
+			browser.ServeHTTP(w, &listing)
+			return
+		}
+
+		notFound(w, req)
+	}
+}
+
+// serveStaticFile writes the named regular file and reports whether it existed
+func serveStaticFile(w http.ResponseWriter, req *http.Request, fs http.FileSystem, name string) bool {
+	f, err := fs.Open(name)
+	if err != nil {
+		return false
+	}
+	defer f.Close()
+
+	info, err := f.Stat()
+	if err != nil || info.IsDir() {
+		return false
+	}
+
+	http.ServeContent(w, req, info.Name(), info.ModTime(), f)
+	return true
+}
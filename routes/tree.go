@@ -0,0 +1,396 @@
+package routes
+
+import (
+	"fmt"
+	"net/http"
+	"strings"
+)
+
+type nodeKind uint8
+
+const (
+	staticNode nodeKind = iota
+	paramNode
+	catchAllNode
+)
+
+// node is one entry of the routing tree. Static nodes hold a compressed
+// run of literal path bytes, param nodes match exactly one path segment
+// and catch-all nodes match the rest of the path, slashes included.
+type node struct {
+	kind     nodeKind
+	path     string // literal prefix for static nodes, parameter name for param nodes
+	indices  string // first byte of every static child, same order as children
+	children []*node
+	params   []*node // param children, tried in registration order
+	catchAll *node
+	routes   map[string][]*Route // per method, first route whose conditions pass wins
+
+	constraint *constraint // optional value check for param nodes
+}
+
+// token is one piece of a parsed route pattern.
+type token struct {
+	kind       nodeKind
+	text       string
+	constraint *constraint
+	optional   bool
+}
+
+// Param is a single route parameter captured while walking the tree.
+type Param struct {
+	Key   string
+	Value string
+}
+
+// parsePattern splits a pattern like "/users/{id:int}/posts" into static
+// and parameter tokens. Parameters must span a whole path segment and may
+// carry a constraint after a colon. A "?" after the name makes the
+// parameter optional, which is only allowed in trailing segments. A final
+// segment written {name...} or *name captures the rest of the path.
+func parsePattern(pattern string) ([]token, error) {
+	if !strings.HasPrefix(pattern, "/") {
+		return nil, fmt.Errorf("pattern %q must begin with '/'", pattern)
+	}
+
+	var tokens []token
+	var static strings.Builder
+
+	for i := 0; i < len(pattern); {
+		c := pattern[i]
+		if c == '}' {
+			return nil, fmt.Errorf("pattern %q has unexpected '}' at offset %d", pattern, i)
+		}
+		if c == '*' && pattern[i-1] == '/' {
+			name := pattern[i+1:]
+			if name == "" || strings.ContainsAny(name, "/{}") {
+				return nil, fmt.Errorf("pattern %q: catch-all *name must be the last segment", pattern)
+			}
+			if static.Len() > 0 {
+				tokens = append(tokens, token{kind: staticNode, text: static.String()})
+				static.Reset()
+			}
+			tokens = append(tokens, token{kind: catchAllNode, text: name})
+			break
+		}
+		if c != '{' {
+			static.WriteByte(c)
+			i++
+			continue
+		}
+
+		end := closingBrace(pattern, i)
+		if end < 0 {
+			return nil, fmt.Errorf("pattern %q has unclosed '{' at offset %d", pattern, i)
+		}
+
+		tok, err := parseParam(pattern[i+1 : end])
+		if err != nil {
+			return nil, fmt.Errorf("pattern %q at offset %d: %w", pattern, i, err)
+		}
+		if pattern[i-1] != '/' || (end+1 < len(pattern) && pattern[end+1] != '/') {
+			return nil, fmt.Errorf("pattern %q: parameter {%s} must span a whole path segment", pattern, tok.text)
+		}
+		if tok.kind == catchAllNode && end+1 < len(pattern) {
+			return nil, fmt.Errorf("pattern %q: catch-all {%s...} must be the last segment", pattern, tok.text)
+		}
+
+		if static.Len() > 0 {
+			tokens = append(tokens, token{kind: staticNode, text: static.String()})
+			static.Reset()
+		}
+		tokens = append(tokens, tok)
+		i = end + 1
+	}
+
+	if static.Len() > 0 {
+		tokens = append(tokens, token{kind: staticNode, text: static.String()})
+	}
+
+	seen := make(map[string]bool)
+	for _, tok := range tokens {
+		if tok.kind == staticNode {
+			continue
+		}
+		if seen[tok.text] {
+			return nil, fmt.Errorf("pattern %q repeats parameter {%s}", pattern, tok.text)
+		}
+		seen[tok.text] = true
+	}
+
+	optional := false
+	for _, tok := range tokens {
+		if tok.kind != paramNode {
+			continue
+		}
+		if optional && !tok.optional {
+			return nil, fmt.Errorf("pattern %q: required parameter {%s} follows an optional one", pattern, tok.text)
+		}
+		optional = tok.optional
+	}
+	if optional && tokens[len(tokens)-1].kind != paramNode {
+		return nil, fmt.Errorf("pattern %q: optional parameters must be in trailing segments", pattern)
+	}
+
+	return tokens, nil
+}
+
+// closingBrace returns the index of the '}' closing the '{' at start,
+// skipping over nested braces such as regex repetition counts.
+func closingBrace(pattern string, start int) int {
+	depth := 0
+	for i := start; i < len(pattern); i++ {
+		switch pattern[i] {
+		case '{':
+			depth++
+		case '}':
+			depth--
+			if depth == 0 {
+				return i
+			}
+		}
+	}
+	return -1
+}
+
+// parseParam parses the inside of a {...} placeholder.
+func parseParam(spec string) (token, error) {
+	name, rule, hasRule := strings.Cut(spec, ":")
+
+	tok := token{kind: paramNode}
+	if strings.HasSuffix(name, "...") && !hasRule {
+		tok.kind = catchAllNode
+		name = strings.TrimSuffix(name, "...")
+	}
+	if strings.HasSuffix(name, "?") {
+		tok.optional = true
+		name = strings.TrimSuffix(name, "?")
+	}
+	if name == "" {
+		return tok, fmt.Errorf("empty parameter name")
+	}
+	if tok.kind == catchAllNode && tok.optional {
+		return tok, fmt.Errorf("catch-all {%s...} cannot be optional, it already matches an empty rest", name)
+	}
+	tok.text = name
+
+	if hasRule {
+		c, err := newConstraint(rule)
+		if err != nil {
+			return tok, fmt.Errorf("parameter {%s}: %w", name, err)
+		}
+		tok.constraint = c
+	}
+	return tok, nil
+}
+
+// expandOptional returns every concrete token list an optional pattern
+// stands for, from the full pattern down to the one without any optional
+// parameter. Dropping a parameter also drops the slash before it.
+func expandOptional(tokens []token) [][]token {
+	variants := [][]token{tokens}
+	for len(tokens) > 0 && tokens[len(tokens)-1].optional {
+		tokens = append([]token(nil), tokens[:len(tokens)-1]...)
+
+		last := &tokens[len(tokens)-1]
+		if last.kind == staticNode && len(last.text) > 1 {
+			last.text = strings.TrimSuffix(last.text, "/")
+		} else if last.kind == staticNode && len(tokens) > 1 {
+			tokens = tokens[:len(tokens)-1]
+		}
+		variants = append(variants, tokens)
+	}
+	return variants
+}
+
+// insert walks the tree along tokens, creating or splitting nodes as
+// needed, and returns the node the pattern terminates at.
+func (n *node) insert(tokens []token) *node {
+	if len(tokens) == 0 {
+		return n
+	}
+
+	tok := tokens[0]
+	if tok.kind == catchAllNode {
+		if n.catchAll == nil {
+			n.catchAll = &node{kind: catchAllNode, path: tok.text}
+		}
+		return n.catchAll
+	}
+	if tok.kind == paramNode {
+		for _, child := range n.params {
+			if child.path == tok.text && child.constraint.String() == tok.constraint.String() {
+				return child.insert(tokens[1:])
+			}
+		}
+		child := &node{kind: paramNode, path: tok.text, constraint: tok.constraint}
+		n.params = append(n.params, child)
+		return child.insert(tokens[1:])
+	}
+
+	return n.insertStatic(tok.text, tokens[1:])
+}
+
+// insertStatic adds the literal path below n, sharing and splitting
+// existing static children on their longest common prefix.
+func (n *node) insertStatic(path string, rest []token) *node {
+	idx := strings.IndexByte(n.indices, path[0])
+	if idx < 0 {
+		child := &node{kind: staticNode, path: path}
+		n.indices += string(path[0])
+		n.children = append(n.children, child)
+		return child.insert(rest)
+	}
+
+	child := n.children[idx]
+	common := longestCommonPrefix(child.path, path)
+
+	if common < len(child.path) {
+		split := &node{
+			kind:     staticNode,
+			path:     child.path[:common],
+			indices:  string(child.path[common]),
+			children: []*node{child},
+		}
+		child.path = child.path[common:]
+		n.children[idx] = split
+		child = split
+	}
+
+	if common == len(path) {
+		return child.insert(rest)
+	}
+	return child.insertStatic(path[common:], rest)
+}
+
+// lookup matches path against the subtree below n and returns the route
+// registered for method whose conditions accept req. Static children win
+// over parameters, and a failed branch backtracks to the next candidate.
+func (n *node) lookup(path, method string, req *http.Request, ps *[]Param) *Route {
+	if path == "" {
+		if route := n.route(method, req); route != nil {
+			return route
+		}
+		return n.matchCatchAll(path, method, req, ps)
+	}
+
+	if idx := strings.IndexByte(n.indices, path[0]); idx >= 0 {
+		child := n.children[idx]
+		if strings.HasPrefix(path, child.path) {
+			if found := child.lookup(path[len(child.path):], method, req, ps); found != nil {
+				return found
+			}
+		}
+	}
+
+	if len(n.params) > 0 && path[0] != '/' {
+		end := strings.IndexByte(path, '/')
+		if end < 0 {
+			end = len(path)
+		}
+		value := path[:end]
+
+		for _, child := range n.params {
+			if !child.constraint.matches(value) {
+				continue
+			}
+			*ps = append(*ps, Param{Key: child.path, Value: value})
+			if found := child.lookup(path[end:], method, req, ps); found != nil {
+				return found
+			}
+			*ps = (*ps)[:len(*ps)-1]
+		}
+	}
+
+	return n.matchCatchAll(path, method, req, ps)
+}
+
+// matchCatchAll hands the remaining path to n's catch-all child, if any.
+func (n *node) matchCatchAll(path, method string, req *http.Request, ps *[]Param) *Route {
+	if n.catchAll == nil {
+		return nil
+	}
+	route := n.catchAll.route(method, req)
+	if route != nil {
+		*ps = append(*ps, Param{Key: n.catchAll.path, Value: path})
+	}
+	return route
+}
+
+// collectMethods adds the method of every route whose pattern matches
+// path and whose conditions accept req to methods, following all branches
+// rather than the first match.
+func (n *node) collectMethods(path string, req *http.Request, methods map[string]bool) {
+	if n.catchAll != nil {
+		n.catchAll.addMethods(req, methods)
+	}
+	if path == "" {
+		n.addMethods(req, methods)
+		return
+	}
+
+	if idx := strings.IndexByte(n.indices, path[0]); idx >= 0 {
+		child := n.children[idx]
+		if strings.HasPrefix(path, child.path) {
+			child.collectMethods(path[len(child.path):], req, methods)
+		}
+	}
+
+	if len(n.params) > 0 && path[0] != '/' {
+		end := strings.IndexByte(path, '/')
+		if end < 0 {
+			end = len(path)
+		}
+		value := path[:end]
+
+		for _, child := range n.params {
+			if child.constraint.matches(value) {
+				child.collectMethods(path[end:], req, methods)
+			}
+		}
+	}
+}
+
+func (n *node) addMethods(req *http.Request, methods map[string]bool) {
+	for method := range n.routes {
+		if method != anyMethod && n.route(method, req) != nil {
+			methods[method] = true
+		}
+	}
+}
+
+// route returns the first route for method terminating at n whose
+// conditions accept req, falling back to routes serving any method
+func (n *node) route(method string, req *http.Request) *Route {
+	for _, route := range n.routes[method] {
+		if route.accepts(req) {
+			return route
+		}
+	}
+	for _, route := range n.routes[anyMethod] {
+		if route.accepts(req) {
+			return route
+		}
+	}
+	return nil
+}
+
+// add registers route for method at n
+func (n *node) add(method string, route *Route) {
+	if n.routes == nil {
+		n.routes = make(map[string][]*Route)
+	}
+	n.routes[method] = append(n.routes[method], route)
+}
+
+func longestCommonPrefix(a, b string) int {
+	max := len(a)
+	if len(b) < max {
+		max = len(b)
+	}
+	i := 0
+	for i < max && a[i] == b[i] {
+		i++
+	}
+	return i
+}
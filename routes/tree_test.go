@@ -0,0 +1,282 @@
+package routes
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"sort"
+	"strings"
+	"testing"
+)
+
+// buildTree registers a route per spec, written "METHOD /pattern" or just
+// "/pattern" for GET
+func buildTree(tb testing.TB, specs ...string) *node {
+	tb.Helper()
+	tree := &node{}
+	for _, spec := range specs {
+		method, pattern, ok := strings.Cut(spec, " ")
+		if !ok {
+			method, pattern = http.MethodGet, spec
+		}
+		tokens, err := parsePattern(pattern)
+		if err != nil {
+			tb.Fatalf("parsePattern(%q): %v", pattern, err)
+		}
+		route := &Route{Method: method, Pattern: pattern}
+		for _, variant := range expandOptional(tokens) {
+			tree.insert(variant).add(method, route)
+		}
+	}
+	return tree
+}
+
+func TestTreeLookup(t *testing.T) {
+	tests := []struct {
+		name    string
+		routes  []string
+		path    string
+		pattern string // "" when nothing matches
+		params  []Param
+	}{
+		{
+			name:    "static beats param",
+			routes:  []string{"/users/{id}", "/users/new"},
+			path:    "/users/new",
+			pattern: "/users/new",
+		},
+		{
+			name:    "param when static differs",
+			routes:  []string{"/users/{id}", "/users/new"},
+			path:    "/users/42",
+			pattern: "/users/{id}",
+			params:  []Param{{"id", "42"}},
+		},
+		{
+			name:    "static prefix falls back to param",
+			routes:  []string{"/users/new", "/users/{id}"},
+			path:    "/users/newest",
+			pattern: "/users/{id}",
+			params:  []Param{{"id", "newest"}},
+		},
+		{
+			name:    "backtrack out of a dead static branch",
+			routes:  []string{"/users/new/form", "/users/{id}/posts"},
+			path:    "/users/new/posts",
+			pattern: "/users/{id}/posts",
+			params:  []Param{{"id", "new"}},
+		},
+		{
+			name:    "constraint rejects value",
+			routes:  []string{"/files/{id:int}", "/files/{name}"},
+			path:    "/files/readme",
+			pattern: "/files/{name}",
+			params:  []Param{{"name", "readme"}},
+		},
+		{
+			name:    "constraint accepts value",
+			routes:  []string{"/files/{id:int}", "/files/{name}"},
+			path:    "/files/7",
+			pattern: "/files/{id:int}",
+			params:  []Param{{"id", "7"}},
+		},
+		{
+			name:    "backtrack through a constrained param",
+			routes:  []string{"/files/{id:int}/raw", "/files/{name}/info"},
+			path:    "/files/7/info",
+			pattern: "/files/{name}/info",
+			params:  []Param{{"name", "7"}},
+		},
+		{
+			name:   "no constraint matches",
+			routes: []string{"/files/{id:int}/raw"},
+			path:   "/files/x/raw",
+		},
+		{
+			name:    "split node keeps its first child",
+			routes:  []string{"/search", "/support", "/s"},
+			path:    "/search",
+			pattern: "/search",
+		},
+		{
+			name:    "split node keeps its second child",
+			routes:  []string{"/search", "/support", "/s"},
+			path:    "/support",
+			pattern: "/support",
+		},
+		{
+			name:    "split point is a route",
+			routes:  []string{"/search", "/support", "/s"},
+			path:    "/s",
+			pattern: "/s",
+		},
+		{
+			name:   "split point prefix is not a route",
+			routes: []string{"/search", "/support"},
+			path:   "/s",
+		},
+		{
+			name:    "catch-all matches the rest",
+			routes:  []string{"/static/{filepath...}"},
+			path:    "/static/css/app.css",
+			pattern: "/static/{filepath...}",
+			params:  []Param{{"filepath", "css/app.css"}},
+		},
+		{
+			name:    "catch-all matches an empty rest",
+			routes:  []string{"/static/{filepath...}"},
+			path:    "/static/",
+			pattern: "/static/{filepath...}",
+			params:  []Param{{"filepath", ""}},
+		},
+		{
+			name:   "catch-all needs its prefix",
+			routes: []string{"/static/{filepath...}"},
+			path:   "/static",
+		},
+		{
+			name:    "static beats catch-all",
+			routes:  []string{"/static/{filepath...}", "/static/index"},
+			path:    "/static/index",
+			pattern: "/static/index",
+		},
+		{
+			name:    "optional param present",
+			routes:  []string{"/posts/{page?:int}"},
+			path:    "/posts/2",
+			pattern: "/posts/{page?:int}",
+			params:  []Param{{"page", "2"}},
+		},
+		{
+			name:    "optional param absent",
+			routes:  []string{"/posts/{page?:int}"},
+			path:    "/posts",
+			pattern: "/posts/{page?:int}",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tree := buildTree(t, tt.routes...)
+			req := httptest.NewRequest(http.MethodGet, tt.path, nil)
+			var ps []Param
+			route := tree.lookup(tt.path, http.MethodGet, req, &ps)
+
+			if tt.pattern == "" {
+				if route != nil {
+					t.Fatalf("lookup(%q) = %q, want no match", tt.path, route.Pattern)
+				}
+				return
+			}
+			if route == nil {
+				t.Fatalf("lookup(%q) = no match, want %q", tt.path, tt.pattern)
+			}
+			if route.Pattern != tt.pattern {
+				t.Errorf("lookup(%q) = %q, want %q", tt.path, route.Pattern, tt.pattern)
+			}
+			if len(ps) != 0 || len(tt.params) != 0 {
+				if !reflect.DeepEqual(ps, tt.params) {
+					t.Errorf("lookup(%q) params = %v, want %v", tt.path, ps, tt.params)
+				}
+			}
+		})
+	}
+}
+
+func TestTreeInsertSplitsStaticNodes(t *testing.T) {
+	tree := buildTree(t, "/search", "/support")
+
+	if len(tree.children) != 1 || tree.children[0].path != "/s" {
+		t.Fatalf("root children = %v, want a single shared /s node", childPaths(tree))
+	}
+	split := tree.children[0]
+	if got := childPaths(split); !reflect.DeepEqual(got, []string{"earch", "upport"}) {
+		t.Errorf("children of /s = %v, want [earch upport]", got)
+	}
+	if split.indices != "eu" {
+		t.Errorf("indices of /s = %q, want %q", split.indices, "eu")
+	}
+	if split.routes != nil {
+		t.Errorf("split node /s has routes %v, want none", split.routes)
+	}
+}
+
+func childPaths(n *node) []string {
+	paths := make([]string, len(n.children))
+	for i, child := range n.children {
+		paths[i] = child.path
+	}
+	return paths
+}
+
+func TestTreeCollectMethods(t *testing.T) {
+	tree := buildTree(t,
+		"GET /items/{id}",
+		"POST /items/{id}",
+		"DELETE /items/{id:int}",
+		"PUT /items/special",
+		"GET /assets/{path...}",
+		"PATCH /assets/{path...}",
+	)
+
+	tests := []struct {
+		path string
+		want []string
+	}{
+		{"/items/5", []string{"DELETE", "GET", "POST"}},
+		{"/items/abc", []string{"GET", "POST"}},
+		{"/items/special", []string{"GET", "POST", "PUT"}},
+		{"/assets/", []string{"GET", "PATCH"}},
+		{"/assets/js/app.js", []string{"GET", "PATCH"}},
+		{"/missing", nil},
+	}
+	for _, tt := range tests {
+		t.Run(tt.path, func(t *testing.T) {
+			methods := make(map[string]bool)
+			tree.collectMethods(tt.path, httptest.NewRequest(http.MethodOptions, tt.path, nil), methods)
+
+			var got []string
+			for method := range methods {
+				got = append(got, method)
+			}
+			sort.Strings(got)
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("collectMethods(%q) = %v, want %v", tt.path, got, tt.want)
+			}
+		})
+	}
+}
+
+// BenchmarkLookup shows that lookup cost depends on the path, not on how
+// many routes are registered
+func BenchmarkLookup(b *testing.B) {
+	for _, count := range []int{10, 100, 1000} {
+		b.Run(fmt.Sprintf("routes=%d", count), func(b *testing.B) {
+			specs := make([]string, 0, count)
+			for i := 0; len(specs) < count; i++ {
+				specs = append(specs,
+					fmt.Sprintf("/api/v1/resource%d", i),
+					fmt.Sprintf("/api/v1/resource%d/{id:int}", i),
+					fmt.Sprintf("/api/v1/resource%d/{id:int}/items/{item}", i),
+				)
+			}
+			tree := buildTree(b, specs[:count]...)
+
+			// The last complete resource is the worst case for a scan
+			last := count/3 - 1
+			path := fmt.Sprintf("/api/v1/resource%d/42", last)
+			req := httptest.NewRequest(http.MethodGet, path, nil)
+			ps := make([]Param, 0, 2)
+
+			b.ReportAllocs()
+			b.ResetTimer()
+			for i := 0; i < b.N; i++ {
+				ps = ps[:0]
+				if tree.lookup(path, http.MethodGet, req, &ps) == nil {
+					b.Fatalf("no route for %s", path)
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,159 @@
+package routes
+
+import (
+	"fmt"
+	"html/template"
+	"net/url"
+	"strings"
+)
+
+// Name registers the route under name for reverse URL generation
+func (route *Route) Name(name string) *Route {
+	r := route.router
+	if route.name != "" && r.named[route.name] == route {
+		delete(r.named, route.name)
+	}
+	if r.named == nil {
+		r.named = make(map[string]*Route)
+	}
+	route.name = name
+	r.named[name] = route
+	return route
+}
+
+// GetName returns the name given to the route, or "" if it has none
+func (route *Route) GetName() string {
+	return route.name
+}
+
+// URL builds the path of the named route from params, checking every
+// value against the parameter's constraint. Optional parameters may be
+// left out. Parameters of a host group are accepted but not used, since
+// only the path is built. A catch-all value may span several segments,
+// none of which may be "." or "..". A non-empty query is encoded after
+// the path.
+func (r *Router) URL(name string, params map[string]string, query url.Values) (string, error) {
+	route, ok := r.named[name]
+	if !ok {
+		return "", fmt.Errorf("routes: no route named %q", name)
+	}
+
+	used := 0
+	var b strings.Builder
+	for _, tok := range route.tokens {
+		if tok.kind == staticNode {
+			b.WriteString(tok.text)
+			continue
+		}
+
+		value, ok := params[tok.text]
+		if !ok {
+			if tok.optional || tok.kind == catchAllNode {
+				// Drop the slash that introduced the missing segments
+				path := strings.TrimSuffix(b.String(), "/")
+				if path == "" || tok.kind == catchAllNode {
+					path = b.String()
+				}
+				b.Reset()
+				b.WriteString(path)
+				break
+			}
+			return "", fmt.Errorf("routes: route %q is missing parameter %q", name, tok.text)
+		}
+		used++
+
+		if tok.kind == catchAllNode {
+			segments := strings.Split(value, "/")
+			for j, segment := range segments {
+				if segment == "." || segment == ".." {
+					return "", fmt.Errorf("routes: parameter %q of route %q does not accept %q", tok.text, name, value)
+				}
+				segments[j] = url.PathEscape(segment)
+			}
+			b.WriteString(strings.Join(segments, "/"))
+			continue
+		}
+
+		if value == "" || !tok.constraint.matches(value) {
+			return "", fmt.Errorf("routes: parameter %q of route %q does not accept %q", tok.text, name, value)
+		}
+		b.WriteString(url.PathEscape(value))
+	}
+
+	// Every given parameter must have ended up in the path, or belong
+	// to the host
+	for _, param := range route.hostParams {
+		if _, ok := params[param]; ok {
+			used++
+		}
+	}
+	if used != len(params) {
+		for key := range params {
+			if !route.hasParam(key) && !route.hasHostParam(key) {
+				return "", fmt.Errorf("routes: route %q has no parameter %q", name, key)
+			}
+		}
+		return "", fmt.Errorf("routes: route %q cannot use parameters after a missing optional one", name)
+	}
+
+	if len(query) > 0 {
+		b.WriteString("?")
+		b.WriteString(query.Encode())
+	}
+	return b.String(), nil
+}
+
+// MustURL is like URL but panics if the URL cannot be built
+func (r *Router) MustURL(name string, params map[string]string, query url.Values) string {
+	u, err := r.URL(name, params, query)
+	if err != nil {
+		panic(err)
+	}
+	return u
+}
+
+// TemplateFuncs returns the template helpers backed by this router, for
+// use with views.Funcs. In a template:
+//
+//	<a href="{{ route "users.edit" "id" .ID }}">Edit</a>
+func (r *Router) TemplateFuncs() template.FuncMap {
+	return template.FuncMap{
+		"route": r.templateURL,
+	}
+}
+
+// templateURL takes the route name followed by alternating parameter
+// names and values
+func (r *Router) templateURL(name string, pairs ...interface{}) (string, error) {
+	if len(pairs)%2 != 0 {
+		return "", fmt.Errorf("routes: route %q called with an odd number of parameter arguments", name)
+	}
+
+	params := make(map[string]string, len(pairs)/2)
+	for i := 0; i < len(pairs); i += 2 {
+		key, ok := pairs[i].(string)
+		if !ok {
+			return "", fmt.Errorf("routes: route %q parameter name %v is not a string", name, pairs[i])
+		}
+		params[key] = fmt.Sprint(pairs[i+1])
+	}
+	return r.URL(name, params, nil)
+}
+
+func (route *Route) hasParam(name string) bool {
+	for _, param := range route.ParamNames {
+		if param == name {
+			return true
+		}
+	}
+	return false
+}
+
+func (route *Route) hasHostParam(name string) bool {
+	for _, param := range route.hostParams {
+		if param == name {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,44 @@
+package routes
+
+import (
+	"net/http"
+	"testing"
+)
+
+func TestURL(t *testing.T) {
+	ok := func(w http.ResponseWriter, req *http.Request) {}
+	router := NewRouter()
+	router.GET("/users/{id:int}/edit", ok).Name("users.edit")
+	router.GET("/files/*path", ok).Name("files")
+	router.Host("{tenant}.example.com").GET("/projects/{project}", ok).Name("tenant.projects")
+
+	tests := []struct {
+		name   string
+		params map[string]string
+		want   string // "" when URL must fail
+	}{
+		{"users.edit", map[string]string{"id": "42"}, "/users/42/edit"},
+		{"users.edit", map[string]string{"id": "abc"}, ""},
+		{"users.edit", map[string]string{"id": "42", "tenant": "acme"}, ""},
+		{"files", map[string]string{"path": "docs/read me.txt"}, "/files/docs/read%20me.txt"},
+		{"files", map[string]string{"path": "docs/../../etc/passwd"}, ""},
+		{"files", map[string]string{"path": "./secret"}, ""},
+		{"files", map[string]string{"path": ".."}, ""},
+		{"files", map[string]string{"path": "v1..2/notes"}, "/files/v1..2/notes"},
+		{"tenant.projects", map[string]string{"project": "site"}, "/projects/site"},
+		{"tenant.projects", map[string]string{"project": "site", "tenant": "acme"}, "/projects/site"},
+		{"tenant.projects", map[string]string{"project": "site", "other": "x"}, ""},
+	}
+	for _, tt := range tests {
+		got, err := router.URL(tt.name, tt.params, nil)
+		if tt.want == "" {
+			if err == nil {
+				t.Errorf("URL(%q, %v) = %q, want an error", tt.name, tt.params, got)
+			}
+			continue
+		}
+		if err != nil || got != tt.want {
+			t.Errorf("URL(%q, %v) = %q, %v, want %q", tt.name, tt.params, got, err, tt.want)
+		}
+	}
+}
@@ -0,0 +1,249 @@
+package routes
+
+import (
+	"fmt"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"unicode"
+)
+
+// RouteError describes a problem with one route registration
+type RouteError struct {
+	Location string // file:line of the call that registered the route
+	Method   string
+	Pattern  string
+	Problem  string
+}
+
+func (e *RouteError) Error() string {
+	if e.Pattern == "" {
+		return fmt.Sprintf("%s: %s", e.Location, e.Problem)
+	}
+	return fmt.Sprintf("%s: %s %s: %s", e.Location, e.Method, e.Pattern, e.Problem)
+}
+
+// RouteErrors lists every problem found by Validate
+type RouteErrors []*RouteError
+
+func (errs RouteErrors) Error() string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "routes: %d problem(s) in the route table", len(errs))
+	for _, err := range errs {
+		b.WriteString("\n  ")
+		b.WriteString(err.Error())
+	}
+	return b.String()
+}
+
+// Validate checks the registered routes and returns RouteErrors listing
+// every invalid pattern (collected in Strict mode, see Router.Strict),
+// duplicate route, ambiguous overlap and route name used twice, including
+// those of mounted routers, or nil when the table is sound.
+func (r *Router) Validate() error {
+	errs := append(RouteErrors{}, r.problems...)
+	errs = append(errs, r.conflicts()...)
+	errs = append(errs, r.duplicateNames()...)
+	for _, route := range r.routes {
+		if sub, ok := route.mounted.(*Router); ok {
+			if subErrs, ok := sub.Validate().(RouteErrors); ok {
+				errs = append(errs, subErrs...)
+			}
+		}
+	}
+	if len(errs) == 0 {
+		return nil
+	}
+	return errs
+}
+
+// fail reports a registration problem. Strict routers keep it for
+// Validate, others panic as they always have.
+func (r *Router) fail(location, method, pattern, problem string) {
+	err := &RouteError{Location: location, Method: method, Pattern: pattern, Problem: problem}
+	if !r.Strict {
+		panic("routes: " + err.Error())
+	}
+	r.problems = append(r.problems, err)
+}
+
+// conflicts compares every pair of routes sharing a host and method.
+// Routes whose paths are told apart by the tree's precedence (static
+// before parameter before catch-all) never conflict; the others are
+// duplicates or ambiguous overlaps resolved only by registration order.
+func (r *Router) conflicts() RouteErrors {
+	var errs RouteErrors
+	for i, later := range r.routes {
+		for _, earlier := range r.routes[:i] {
+			if later.Host != earlier.Host || later.Method != earlier.Method || !sameConditions(later, earlier) {
+				continue
+			}
+
+			overlaps, identical := routesOverlap(earlier, later)
+			if !overlaps {
+				continue
+			}
+			problem := "overlaps " + earlier.Pattern + " registered at " + earlier.location +
+				"; requests matching both are routed by registration order"
+			if identical {
+				problem = "duplicates " + earlier.Pattern + " registered at " + earlier.location +
+					" and is never matched"
+			}
+			errs = append(errs, &RouteError{Location: later.location, Method: later.Method, Pattern: later.Pattern, Problem: problem})
+			break
+		}
+	}
+	return errs
+}
+
+func (r *Router) duplicateNames() RouteErrors {
+	var errs RouteErrors
+	first := make(map[string]*Route)
+	for _, route := range r.routes {
+		if route.name == "" {
+			continue
+		}
+		if earlier, ok := first[route.name]; ok {
+			errs = append(errs, &RouteError{
+				Location: route.location,
+				Method:   route.Method,
+				Pattern:  route.Pattern,
+				Problem:  fmt.Sprintf("name %q is already used by %s %s at %s", route.name, earlier.Method, earlier.Pattern, earlier.location),
+			})
+			continue
+		}
+		first[route.name] = route
+	}
+	return errs
+}
+
+// sameConditions reports whether two routes are selected by the same
+// conditions, e.g. were registered on equally configured versioned groups
+func sameConditions(a, b *Route) bool {
+	if len(a.conditions) != len(b.conditions) {
+		return false
+	}
+	for i := range a.conditions {
+		if a.conditions[i].key != b.conditions[i].key {
+			return false
+		}
+	}
+	return true
+}
+
+// routesOverlap reports whether a path can match both routes, and whether
+// they are identical apart from parameter names
+func routesOverlap(a, b *Route) (overlaps, identical bool) {
+	for _, x := range expandOptional(a.tokens) {
+		for _, y := range expandOptional(b.tokens) {
+			o, i := segmentsOverlap(segments(x), segments(y))
+			if i {
+				return true, true
+			}
+			overlaps = overlaps || o
+		}
+	}
+	return overlaps, false
+}
+
+// segments splits a concrete token list into one token per path segment
+func segments(tokens []token) []token {
+	var segs []token
+	for i, tok := range tokens {
+		if tok.kind != staticNode {
+			segs = append(segs, tok)
+			continue
+		}
+		parts := strings.Split(tok.text, "/")[1:]
+		if i+1 < len(tokens) {
+			// the empty part after the final slash is the next parameter
+			parts = parts[:len(parts)-1]
+		}
+		for _, part := range parts {
+			segs = append(segs, token{kind: staticNode, text: part})
+		}
+	}
+	return segs
+}
+
+func segmentsOverlap(a, b []token) (overlaps, identical bool) {
+	identical = true
+	for i := 0; i < len(a) && i < len(b); i++ {
+		x, y := a[i], b[i]
+		if x.kind != y.kind {
+			return false, false
+		}
+		switch x.kind {
+		case staticNode:
+			if x.text != y.text {
+				return false, false
+			}
+		case paramNode:
+			if x.constraint.String() != y.constraint.String() {
+				if disjoint(x.constraint, y.constraint) {
+					return false, false
+				}
+				identical = false
+			}
+		case catchAllNode:
+			return true, identical
+		}
+	}
+	if len(a) != len(b) {
+		return false, false
+	}
+	return true, identical
+}
+
+// disjointConstraints lists built-in pairs no value satisfies together
+var disjointConstraints = map[[2]string]bool{
+	{"int", "alpha"}:  true,
+	{"int", "uuid"}:   true,
+	{"alpha", "uuid"}: true,
+	{"alnum", "uuid"}: true,
+}
+
+func disjoint(a, b *constraint) bool {
+	x, y := a.String(), b.String()
+	return disjointConstraints[[2]string{x, y}] || disjointConstraints[[2]string{y, x}]
+}
+
+// routesPkg is the import path of this package, used to skip its frames
+var routesPkg = strings.TrimSuffix(funcName(NewRouter), ".NewRouter")
+
+// callerLocation returns the file:line that registered a route: the first
+// caller that is not part of the router's registration methods
+func callerLocation() string {
+	pcs := make([]uintptr, 32)
+	frames := runtime.CallersFrames(pcs[:runtime.Callers(2, pcs)])
+	for {
+		frame, more := frames.Next()
+		if !isRegistrationFrame(frame.Function) {
+			return fmt.Sprintf("%s:%d", shortPath(frame.File), frame.Line)
+		}
+		if !more {
+			return "unknown location"
+		}
+	}
+}
+
+// isRegistrationFrame reports whether function is a Router, RouteGroup or
+// Route method or an unexported helper of this package
+func isRegistrationFrame(function string) bool {
+	name, ok := strings.CutPrefix(function, routesPkg+".")
+	if !ok || name == "" {
+		return false
+	}
+	for _, receiver := range []string{"(*Router)", "(*RouteGroup)", "(*Route)"} {
+		if strings.HasPrefix(name, receiver) {
+			return true
+		}
+	}
+	return unicode.IsLower(rune(name[0]))
+}
+
+// shortPath keeps the directory and file name, e.g. "routes/web.go"
+func shortPath(file string) string {
+	dir, base := filepath.Split(file)
+	return filepath.Join(filepath.Base(dir), base)
+}
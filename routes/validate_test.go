@@ -0,0 +1,26 @@
+package routes
+
+import (
+	"net/http"
+	"strings"
+	"testing"
+)
+
+func TestValidateComparesConditionsByValue(t *testing.T) {
+	ok := func(w http.ResponseWriter, req *http.Request) {}
+	v2 := VersionConfig{Name: "v2", Prefix: "/api", Header: "X-API-Version"}
+
+	router := NewRouter()
+	router.Version(VersionConfig{Name: "v1", Prefix: "/api", Header: "X-API-Version", Default: true}).GET("/users", ok)
+	router.Version(v2).GET("/users", ok)
+	if err := router.Validate(); err != nil {
+		t.Fatalf("different versions reported as conflicting: %v", err)
+	}
+
+	// An equal configuration in a separately built group still conflicts
+	router.Version(v2).GET("/users", ok)
+	err := router.Validate()
+	if err == nil || !strings.Contains(err.Error(), "duplicates /api/users") {
+		t.Fatalf("Validate() = %v, want a duplicate of /api/users", err)
+	}
+}
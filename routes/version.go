@@ -0,0 +1,144 @@
+package routes
+
+import (
+	"fmt"
+	"mime"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// VersionConfig describes one API version served by a versioned group
+type VersionConfig struct {
+	// Name identifies the version, e.g. "v2". A leading "v" is optional
+	// when clients request it ("2" and "v2" are the same version).
+	Name string
+
+	// Prefix is added to the group's routes, e.g. "/api/v2". Versions that
+	// share a prefix are told apart by Header or MediaType.
+	Prefix string
+
+	// Header names a request header carrying the version, e.g.
+	// "X-API-Version: 2"
+	Header string
+
+	// MediaType is a vendor media type prefix matched against Accept, e.g.
+	// "application/vnd.app" matches "application/vnd.app.v2+json" and
+	// "application/vnd.app+json; version=2"
+	MediaType string
+
+	// Default serves requests that do not ask for any version
+	Default bool
+
+	// Deprecation and Sunset, when set, are sent as the Deprecation
+	// (RFC 9745) and Sunset (RFC 8594) response headers
+	Deprecation time.Time
+	Sunset      time.Time
+}
+
+// Version returns a group serving one API version. With only a Prefix the
+// version is chosen by path; with Header or MediaType the same patterns
+// can be registered for several versions and the request picks one.
+//
+//	v1 := router.Version(routes.VersionConfig{Name: "v1", Prefix: "/api", MediaType: "application/vnd.app", Default: true})
+//	v2 := router.Version(routes.VersionConfig{Name: "v2", Prefix: "/api", MediaType: "application/vnd.app"})
+//	v1.GET("/users", controllers.UsersV1)
+//	v2.GET("/users", controllers.UsersV2)
+func (r *Router) Version(config VersionConfig, middlewares ...func(http.Handler) http.Handler) *RouteGroup {
+	return r.Group("").Version(config, middlewares...)
+}
+
+// Version returns a versioned group nested below rg
+func (rg *RouteGroup) Version(config VersionConfig, middlewares ...func(http.Handler) http.Handler) *RouteGroup {
+	group := rg.Group(config.Prefix, append([]func(http.Handler) http.Handler{versionHeaders(config)}, middlewares...)...)
+
+	if config.Header != "" || config.MediaType != "" {
+		group.conditions = append(append([]condition{}, rg.conditions...), condition{
+			key: fmt.Sprintf("version %s header=%q media=%q default=%t",
+				strings.TrimPrefix(strings.ToLower(config.Name), "v"), config.Header, config.MediaType, config.Default),
+			match: func(req *http.Request) bool {
+				requested := requestedVersion(req, config)
+				if requested == "" {
+					return config.Default
+				}
+				return sameVersion(requested, config.Name)
+			},
+		})
+	}
+	return group
+}
+
+// condition selects the requests a route serves beyond method and path.
+// key describes it, so that routes of equally configured groups are
+// recognized as conflicting.
+type condition struct {
+	key   string
+	match func(*http.Request) bool
+}
+
+// versionHeaders sets the deprecation headers and tells caches which
+// request header selected the version
+func versionHeaders(config VersionConfig) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+			header := w.Header()
+			if !config.Deprecation.IsZero() {
+				header.Set("Deprecation", "@"+strconv.FormatInt(config.Deprecation.Unix(), 10))
+			}
+			if !config.Sunset.IsZero() {
+				header.Set("Sunset", config.Sunset.UTC().Format(http.TimeFormat))
+			}
+			if config.Header != "" {
+				header.Add("Vary", config.Header)
+			}
+			if config.MediaType != "" {
+				header.Add("Vary", "Accept")
+			}
+			next.ServeHTTP(w, req)
+		})
+	}
+}
+
+// requestedVersion returns the version asked for by req, or "" if none
+func requestedVersion(req *http.Request, config VersionConfig) string {
+	if config.Header != "" {
+		if v := strings.TrimSpace(req.Header.Get(config.Header)); v != "" {
+			return v
+		}
+	}
+
+	if config.MediaType == "" {
+		return ""
+	}
+	for _, accept := range req.Header.Values("Accept") {
+		for _, part := range strings.Split(accept, ",") {
+			mediaType, params, err := mime.ParseMediaType(strings.TrimSpace(part))
+			if err != nil || !strings.HasPrefix(mediaType, config.MediaType) {
+				continue
+			}
+
+			// application/vnd.app.v2+json
+			rest := strings.TrimPrefix(mediaType, config.MediaType)
+			if plus := strings.IndexByte(rest, '+'); plus >= 0 {
+				rest = rest[:plus]
+			}
+			if strings.HasPrefix(rest, ".") && len(rest) > 1 {
+				return rest[1:]
+			}
+
+			// application/vnd.app+json; version=2
+			if v := params["version"]; v != "" {
+				return v
+			}
+		}
+	}
+	return ""
+}
+
+func sameVersion(a, b string) bool {
+	trim := func(v string) string {
+		return strings.TrimPrefix(strings.ToLower(v), "v")
+	}
+	return trim(a) == trim(b)
+}
@@ -0,0 +1,28 @@
+package routes
+
+import (
+	"net/http"
+
+	"enzovu/websocket"
+)
+
+// WebSocket serves WebSocket connections at pattern. The handshake runs
+// through the global and route middlewares, so authentication applies,
+// and the route parameters are read from conn.Request():
+//
+//	router.WebSocket("/rooms/{room}", func(conn *websocket.Conn) {
+//		room := routes.GetParam(conn.Request(), "room")
+//		...
+//	}, middleware.AuthMiddleware)
+//
+// The connection is closed when handler returns. To change limits or
+// accepted origins, register websocket.Handler(handler, config) with GET.
+func (r *Router) WebSocket(pattern string, handler func(conn *websocket.Conn), middlewares ...func(http.Handler) http.Handler) *Route {
+	return r.Group("").WebSocket(pattern, handler, middlewares...)
+}
+
+// WebSocket serves WebSocket connections below the group's prefix, see
+// Router.WebSocket
+func (rg *RouteGroup) WebSocket(pattern string, handler func(conn *websocket.Conn), middlewares ...func(http.Handler) http.Handler) *Route {
+	return rg.addRoute(http.MethodGet, pattern, websocket.Handler(handler), handlerName(handler), middlewares)
+}
@@ -0,0 +1,314 @@
+// Package server starts the application as the configuration asks: in
+// development under the hot-reload supervisor, otherwise as a plain HTTP
+// or HTTPS server. Both `go run .` and `go-craft serve` end up in Run.
+package server
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"net"
+	"net/http"
+	"os"
+	"os/signal"
+	"strings"
+	"syscall"
+	"time"
+
+	"enzovu/bootstrap"
+	"enzovu/config"
+	"enzovu/devserver"
+	"enzovu/livereload"
+	"enzovu/routes"
+	"enzovu/views"
+	"enzovu/watcher"
+)
+
+// Run serves the application until it receives an interrupt. Load the
+// configuration before calling it.
+func Run(cfg *config.Config) error {
+	if (cfg.Server.TLSCert == "") != (cfg.Server.TLSKey == "") {
+		return errors.New("HTTPS needs both a TLS certificate and a key")
+	}
+
+	// A child of the development supervisor serves on the inherited socket
+	if devserver.Supervised() {
+		return runSupervised(cfg)
+	}
+
+	displayWelcomeMessage()
+
+	addr := net.JoinHostPort(cfg.App.Host, cfg.App.Port)
+	if cfg.App.Environment == "development" && cfg.Watch.Enabled {
+		return runWithHotReload(cfg, addr)
+	}
+
+	// Initialize application
+	bootstrap.InitializeApp()
+	return runDirect(cfg, addr)
+}
+
+// runDirect serves in this process, as in production
+func runDirect(cfg *config.Config, addr string) error {
+	// Setup routes
+	router, err := routes.SetupRoutes()
+	if err != nil {
+		return fmt.Errorf("invalid routes: %w", err)
+	}
+
+	// Create HTTP server
+	server := newServer(cfg, router)
+	listener, err := net.Listen("tcp", addr)
+	if err != nil {
+		return err
+	}
+
+	// Start server
+	fmt.Printf("🚀 Enzovu server starting on %s\n", displayURL(cfg))
+	fmt.Printf("📊 Environment: %s\n", cfg.App.Environment)
+	fmt.Println("🎯 Press Ctrl+C to shutdown")
+	fmt.Println()
+
+	// Setup graceful shutdown
+	go func() {
+		c := make(chan os.Signal, 1)
+		signal.Notify(c, os.Interrupt, syscall.SIGTERM)
+		<-c
+
+		fmt.Println("\n🛑 Shutting down gracefully...")
+		ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+		defer cancel()
+
+		if err := server.Shutdown(ctx); err != nil {
+			log.Printf("❌ Server forced to shutdown: %v", err)
+		}
+		fmt.Println("✅ Server exited successfully")
+		os.Exit(0)
+	}()
+
+	if err := serve(cfg, server, listener); err != http.ErrServerClosed {
+		return err
+	}
+	return nil
+}
+
+// runSupervised serves the application as a child of the development
+// supervisor, until the supervisor replaces it
+func runSupervised(cfg *config.Config) error {
+	bootstrap.InitializeApp()
+
+	router, err := routes.SetupRoutes()
+	if err != nil {
+		return fmt.Errorf("invalid routes: %w", err)
+	}
+
+	listener, err := devserver.Listener()
+	if err != nil {
+		return fmt.Errorf("cannot use the supervisor's socket: %w", err)
+	}
+
+	// Refresh the browser when templates and assets change. Live-reload
+	// streams stay open until closed, which would hold up the graceful
+	// shutdown.
+	server := newServer(cfg, livereload.Wrap(router))
+	server.RegisterOnShutdown(livereload.Shutdown)
+	go func() {
+		if err := serve(cfg, server, listener); err != http.ErrServerClosed {
+			log.Fatalf("❌ Server failed: %v", err)
+		}
+	}()
+	if err := devserver.Ready(); err != nil {
+		return fmt.Errorf("cannot reach the supervisor: %w", err)
+	}
+
+	ctx, stopWatching := context.WithCancel(context.Background())
+	defer stopWatching()
+	watchAssets(ctx, cfg)
+
+	// Failed rebuilds leave this process serving; show why in the browser
+	go func() {
+		for e := range devserver.BuildErrors() {
+			livereload.ShowError(e.Title, e.Details)
+		}
+	}()
+
+	// The supervisor sends SIGTERM once a newer build is serving; requests
+	// in flight are finished first
+	c := make(chan os.Signal, 1)
+	signal.Notify(c, os.Interrupt, syscall.SIGTERM)
+	<-c
+
+	shutdownCtx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	defer cancel()
+	if err := server.Shutdown(shutdownCtx); err != nil {
+		log.Printf("❌ Server forced to shutdown: %v", err)
+	}
+	return nil
+}
+
+func newServer(cfg *config.Config, handler http.Handler) *http.Server {
+	return &http.Server{
+		Handler:      handler,
+		ReadTimeout:  cfg.Server.ReadTimeout,
+		WriteTimeout: cfg.Server.WriteTimeout,
+		IdleTimeout:  cfg.Server.IdleTimeout,
+	}
+}
+
+// serve serves HTTPS when a certificate is configured, HTTP otherwise
+func serve(cfg *config.Config, server *http.Server, listener net.Listener) error {
+	if cfg.Server.TLSCert != "" {
+		return server.ServeTLS(listener, cfg.Server.TLSCert, cfg.Server.TLSKey)
+	}
+	return server.Serve(listener)
+}
+
+func runWithHotReload(cfg *config.Config, addr string) error {
+	supervisor, err := devserver.New(devserver.Config{
+		Addr:    addr,
+		TLSCert: cfg.Server.TLSCert,
+		TLSKey:  cfg.Server.TLSKey,
+	})
+	if err != nil {
+		return err
+	}
+
+	// Watch before the first build, so edits made meanwhile are seen
+	files, err := newWatcher(cfg)
+	if err != nil {
+		return fmt.Errorf("cannot watch files: %w", err)
+	}
+	files.On(func(changed []string) {
+		fmt.Printf("📝 %s changed, rebuilding...\n", describeChanges(changed))
+
+		// Build and swap in the new binary; on failure the error is
+		// shown in the terminal and the browser
+		if supervisor.Reload() == nil {
+			fmt.Println("✅ Application reloaded successfully!")
+		}
+	}, "**/*.go", "go.mod", "go.sum")
+
+	fmt.Println("🔥 Hot reload enabled - edit any .go file to rebuild!")
+	fmt.Printf("🚀 Enzovu server starting on %s\n", displayURL(cfg))
+	fmt.Printf("📊 Environment: %s\n", cfg.App.Environment)
+	fmt.Println("🎯 Press Ctrl+C to shutdown")
+	fmt.Println()
+
+	// Setup graceful shutdown before the first build. The children get
+	// Ctrl+C as well, so the supervisor must learn of it right away to
+	// tell their exits from crashes.
+	c := make(chan os.Signal, 1)
+	signal.Notify(c, os.Interrupt, syscall.SIGTERM)
+
+	go func() {
+		<-c
+		fmt.Println("\n🛑 Shutting down gracefully...")
+		ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+		defer cancel()
+
+		if err := supervisor.Shutdown(ctx); err != nil {
+			log.Printf("❌ Server forced to shutdown: %v", err)
+		}
+		fmt.Println("✅ Server exited successfully")
+		os.Exit(0)
+	}()
+
+	supervisor.Reload()
+
+	return files.Run(context.Background())
+}
+
+// watchAssets reloads templates and refreshes the browser on template and
+// asset changes while a supervised child serves; Go changes are left to
+// the supervisor
+func watchAssets(ctx context.Context, cfg *config.Config) {
+	files, err := newWatcher(cfg)
+	if err != nil {
+		log.Printf("⚠️  Cannot watch templates and assets: %v", err)
+		return
+	}
+	files.On(func(changed []string) {
+		views.ClearCache()
+		livereload.Reload()
+		fmt.Printf("🎨 %s changed, templates reloaded\n", describeChanges(changed))
+	}, "resources/views/**/*.html")
+	files.On(func(changed []string) {
+		// Stylesheets are swapped in place, anything else reloads the page
+		var stylesheets []string
+		for _, name := range changed {
+			if !strings.HasSuffix(name, ".css") {
+				livereload.Reload()
+				stylesheets = nil
+				break
+			}
+			stylesheets = append(stylesheets, strings.TrimPrefix(name, "public/"))
+		}
+		livereload.ReplaceCSS(stylesheets...)
+		fmt.Printf("🎨 %s changed, assets refreshed\n", describeChanges(changed))
+	}, "public/**")
+	go files.Run(ctx)
+}
+
+// newWatcher watches the project with the rules from the configuration
+func newWatcher(cfg *config.Config) (*watcher.Watcher, error) {
+	return watcher.New(watcher.Config{
+		Include:  cfg.Watch.Include,
+		Exclude:  cfg.Watch.Exclude,
+		Debounce: cfg.Watch.Debounce,
+		Poll:     cfg.Watch.Poll,
+	})
+}
+
+// describeChanges names the first changed file and counts the rest
+func describeChanges(changed []string) string {
+	if len(changed) == 1 {
+		return changed[0]
+	}
+	return fmt.Sprintf("%s and %d more", changed[0], len(changed)-1)
+}
+
+// displayURL is the address to open in a browser
+func displayURL(cfg *config.Config) string {
+	scheme := "http"
+	if cfg.Server.TLSCert != "" {
+		scheme = "https"
+	}
+	host := cfg.App.Host
+	if host == "" || host == "0.0.0.0" || host == "::" {
+		host = "localhost"
+	}
+	return fmt.Sprintf("%s://%s", scheme, net.JoinHostPort(host, cfg.App.Port))
+}
+
+func displayWelcomeMessage() {
+	elephant := `
+   _..--""-.                  .-""--.._
+.-'         \ __...----...__ /         '-.
+.'      .:::...,'              ',...:::.      '.
+(     .''''''::;                  ;::''''''.     )
+\             '-)              (-'             /
+\             /                \             /
+ \          .'.-.            .-.'.          /
+  \         | \0|            |0/ |         /
+   |         \  |   .-==-.   |  /         |
+   \         '/';          ;'\'         /
+    '.._      (_ |  .-==-.  | _)      _..'
+        '""'-./ /'        '\ \.-'"'"
+             / /';   .==.   ;'\ \
+        .---/ /   \  .==.  /   / \---.
+        |   | |   / .''''. \   | |   |
+        |   | |   \ \    / /   | |   |
+        |   \ \   /  '""'  \   / /   |
+        \    \ \_/          \_/ /    /
+         \    \  -._      _. -  /    /
+          \    \    '""""'    /    /
+           \    \     _    /    /
+            \    \   /----\   /    /
+`
+
+	fmt.Println(elephant)
+	fmt.Println("🐘 Welcome to Enzovu Framework!")
+	fmt.Printf("📅 %s\n", time.Now().Format("2006-01-02 15:04:05"))
+	fmt.Println()
+}
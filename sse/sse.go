@@ -0,0 +1,298 @@
+// Package sse streams Server-Sent Events to browsers:
+//
+//	router.GET("/jobs/{id}/progress", sse.Handler(func(s *sse.Stream) error {
+//		for p := range job.Progress(s.LastEventID()) {
+//			if err := s.Send(sse.Event{ID: p.ID, Event: "progress", Data: p}); err != nil {
+//				return err // the client went away
+//			}
+//		}
+//		return nil
+//	}))
+//
+// The stream sends keep-alive comments while idle, so proxies keep the
+// connection open, and stops once the client disconnects.
+package sse
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+)
+
+// DefaultKeepAlive is how often an idle stream sends a comment
+const DefaultKeepAlive = 15 * time.Second
+
+// ErrStreamingUnsupported is returned by New, before anything is written,
+// when the response writer cannot flush, which would hold events back in
+// a buffer
+var ErrStreamingUnsupported = errors.New("sse: response writer does not support flushing")
+
+// ErrClosed is returned when sending on a stream that was closed
+var ErrClosed = errors.New("sse: stream closed")
+
+// Event is one message of the stream. Data is sent as is when it is a
+// string or []byte, and as JSON otherwise.
+type Event struct {
+	ID    string        // stored by the browser and sent back as Last-Event-ID
+	Event string        // event type, "message" when empty
+	Data  interface{}   // payload, split into one data line per line
+	Retry time.Duration // reconnection delay hint for the browser
+}
+
+// Config tunes a stream
+type Config struct {
+	KeepAlive time.Duration // interval of keep-alive comments, DefaultKeepAlive when zero, none when negative
+	Retry     time.Duration // reconnection delay sent when the stream opens, none when zero
+}
+
+// Stream is an open event stream. Its methods may be called from several
+// goroutines.
+type Stream struct {
+	w       http.ResponseWriter
+	r       *http.Request
+	rc      *http.ResponseController
+	mu      sync.Mutex
+	closed  bool
+	err     error
+	done    chan struct{}
+	stopped sync.WaitGroup
+}
+
+// New starts an event stream on w. Call Close before the handler
+// returns; Handler does that itself.
+func New(w http.ResponseWriter, r *http.Request, config ...Config) (*Stream, error) {
+	var cfg Config
+	if len(config) > 0 {
+		cfg = config[0]
+	}
+	if cfg.KeepAlive == 0 {
+		cfg.KeepAlive = DefaultKeepAlive
+	}
+
+	// Checked before the header goes out, so the caller can still answer
+	// with an error
+	if !canFlush(w) {
+		return nil, ErrStreamingUnsupported
+	}
+
+	rc := http.NewResponseController(w)
+	header := w.Header()
+	header.Set("Content-Type", "text/event-stream")
+	header.Set("Cache-Control", "no-cache")
+	header.Set("Connection", "keep-alive")
+	header.Set("X-Accel-Buffering", "no") // nginx would buffer the stream otherwise
+	w.WriteHeader(http.StatusOK)
+	if err := rc.Flush(); err != nil {
+		return nil, err
+	}
+	// The server's write timeout would cut the stream off
+	rc.SetWriteDeadline(time.Time{})
+
+	s := &Stream{w: w, r: r, rc: rc, done: make(chan struct{})}
+	if cfg.Retry > 0 {
+		if err := s.write(fmt.Sprintf("retry: %d\n\n", cfg.Retry.Milliseconds())); err != nil {
+			return nil, err
+		}
+	}
+	s.stopped.Add(1)
+	go s.watch(cfg.KeepAlive)
+	return s, nil
+}
+
+// canFlush reports whether the innermost writer w wraps can flush.
+// Wrappers pass flushes through whether or not it can.
+func canFlush(w http.ResponseWriter) bool {
+	for {
+		wrapper, ok := w.(interface{ Unwrap() http.ResponseWriter })
+		if !ok {
+			break
+		}
+		w = wrapper.Unwrap()
+	}
+	switch w.(type) {
+	case interface{ FlushError() error }, http.Flusher:
+		return true
+	}
+	return false
+}
+
+// Handler adapts fn to an http.HandlerFunc that opens the stream, runs fn
+// and closes the stream. A writer that cannot stream is answered with 500;
+// later errors are only logged, since the response has already started.
+func Handler(fn func(s *Stream) error, config ...Config) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		s, err := New(w, r, config...)
+		if err != nil {
+			log.Println("SSE error:", err)
+			if errors.Is(err, ErrStreamingUnsupported) {
+				http.Error(w, http.StatusText(http.StatusInternalServerError), http.StatusInternalServerError)
+			}
+			return
+		}
+		defer s.Close()
+
+		if err := fn(s); err != nil && !s.Disconnected() {
+			log.Println("SSE error:", err)
+		}
+	}
+}
+
+// Request returns the request the stream answers
+func (s *Stream) Request() *http.Request {
+	return s.r
+}
+
+// LastEventID returns the ID of the last event the client saw before
+// reconnecting, or "" on the first connection. The lastEventId query
+// parameter is accepted for EventSource polyfills that cannot set headers.
+func (s *Stream) LastEventID() string {
+	if id := s.r.Header.Get("Last-Event-ID"); id != "" {
+		return id
+	}
+	return s.r.URL.Query().Get("lastEventId")
+}
+
+// Done is closed when the client disconnects or the stream is closed
+func (s *Stream) Done() <-chan struct{} {
+	return s.done
+}
+
+// Disconnected reports whether the client has gone away
+func (s *Stream) Disconnected() bool {
+	return s.r.Context().Err() != nil
+}
+
+// Send writes an event and flushes it to the client. It fails once the
+// client has disconnected.
+func (s *Stream) Send(e Event) error {
+	var b strings.Builder
+	for _, field := range []struct{ name, value string }{{"id", e.ID}, {"event", e.Event}} {
+		if strings.ContainsAny(field.value, "\r\n") {
+			return fmt.Errorf("sse: event %s must not contain line breaks", field.name)
+		}
+		if field.value != "" {
+			fmt.Fprintf(&b, "%s: %s\n", field.name, field.value)
+		}
+	}
+	if e.Retry > 0 {
+		fmt.Fprintf(&b, "retry: %d\n", e.Retry.Milliseconds())
+	}
+
+	data, err := encode(e.Data)
+	if err != nil {
+		return err
+	}
+	// An event with only an ID or retry hint updates the client without
+	// being dispatched
+	if data != "" || e.Event != "" || e.ID == "" && e.Retry == 0 {
+		for _, line := range strings.Split(strings.NewReplacer("\r\n", "\n", "\r", "\n").Replace(data), "\n") {
+			fmt.Fprintf(&b, "data: %s\n", line)
+		}
+	}
+	b.WriteString("\n")
+	return s.write(b.String())
+}
+
+// Data sends a plain message event
+func (s *Stream) Data(data interface{}) error {
+	return s.Send(Event{Data: data})
+}
+
+// Comment sends a comment line, which clients ignore
+func (s *Stream) Comment(text string) error {
+	var b strings.Builder
+	for _, line := range strings.Split(text, "\n") {
+		fmt.Fprintf(&b, ": %s\n", line)
+	}
+	b.WriteString("\n")
+	return s.write(b.String())
+}
+
+// Close stops the keep-alive and waits for it to finish. Nothing is
+// written afterwards, so the handler may return.
+func (s *Stream) Close() {
+	s.shutdown()
+	s.stopped.Wait()
+}
+
+func (s *Stream) shutdown() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if !s.closed {
+		s.closed = true
+		close(s.done)
+	}
+}
+
+func (s *Stream) write(text string) error {
+	if err := s.r.Context().Err(); err != nil {
+		return err
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.closed {
+		return ErrClosed
+	}
+	if s.err != nil {
+		return s.err
+	}
+	if _, err := s.w.Write([]byte(text)); err != nil {
+		s.err = err
+		return err
+	}
+	if err := s.rc.Flush(); err != nil {
+		s.err = err
+		return err
+	}
+	return nil
+}
+
+// watch sends keep-alive comments and closes the stream when the client
+// disconnects
+func (s *Stream) watch(keepAlive time.Duration) {
+	defer s.stopped.Done()
+
+	var tick <-chan time.Time
+	if keepAlive > 0 {
+		ticker := time.NewTicker(keepAlive)
+		defer ticker.Stop()
+		tick = ticker.C
+	}
+	for {
+		select {
+		case <-tick:
+			if err := s.write(": keep-alive\n\n"); err != nil {
+				s.shutdown()
+				return
+			}
+		case <-s.r.Context().Done():
+			s.shutdown()
+			return
+		case <-s.done:
+			return
+		}
+	}
+}
+
+// encode renders event data as text
+func encode(data interface{}) (string, error) {
+	switch d := data.(type) {
+	case nil:
+		return "", nil
+	case string:
+		return d, nil
+	case []byte:
+		return string(d), nil
+	}
+	body, err := json.Marshal(data)
+	if err != nil {
+		return "", fmt.Errorf("sse: encoding event data: %w", err)
+	}
+	return string(body), nil
+}
@@ -0,0 +1,182 @@
+package validation
+
+import (
+	"context"
+	"fmt"
+	"net/mail"
+	"reflect"
+	"regexp"
+	"strconv"
+	"strings"
+	"sync"
+	"unicode/utf8"
+
+	"enzovu/database"
+)
+
+func init() {
+	Register("required", required)
+	Register("email", email)
+	Register("min", minRule)
+	Register("max", maxRule)
+	Register("in", in)
+	Register("regex", regex)
+	Register("unique", unique)
+	Register("exists", exists)
+}
+
+func required(_ context.Context, f Field) (string, error) {
+	if isEmpty(f.Value) {
+		return fmt.Sprintf("The %s field is required.", f.Name), nil
+	}
+	return "", nil
+}
+
+func email(_ context.Context, f Field) (string, error) {
+	value := fmt.Sprint(f.Value.Interface())
+	if addr, err := mail.ParseAddress(value); err != nil || addr.Address != value {
+		return fmt.Sprintf("The %s field must be a valid email address.", f.Name), nil
+	}
+	return "", nil
+}
+
+func minRule(_ context.Context, f Field) (string, error) {
+	return compare(f, func(n, limit float64) bool { return n >= limit }, map[string]string{
+		"number": "The %s field must be at least %s.",
+		"string": "The %s field must be at least %s characters.",
+		"items":  "The %s field must have at least %s items.",
+	})
+}
+
+func maxRule(_ context.Context, f Field) (string, error) {
+	return compare(f, func(n, limit float64) bool { return n <= limit }, map[string]string{
+		"number": "The %s field must not be greater than %s.",
+		"string": "The %s field must not be greater than %s characters.",
+		"items":  "The %s field must not have more than %s items.",
+	})
+}
+
+// compare checks the size of f against its numeric argument
+func compare(f Field, ok func(n, limit float64) bool, messages map[string]string) (string, error) {
+	limit, err := strconv.ParseFloat(f.Arg, 64)
+	if err != nil {
+		return "", fmt.Errorf("validation: %s needs a numeric limit, got %q", f.Name, f.Arg)
+	}
+
+	var n float64
+	var kind string
+	v := f.Value
+	switch v.Kind() {
+	case reflect.String:
+		n, kind = float64(utf8.RuneCountInString(v.String())), "string"
+	case reflect.Slice, reflect.Map, reflect.Array:
+		n, kind = float64(v.Len()), "items"
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		n, kind = float64(v.Int()), "number"
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		n, kind = float64(v.Uint()), "number"
+	case reflect.Float32, reflect.Float64:
+		n, kind = v.Float(), "number"
+	default:
+		return "", fmt.Errorf("validation: cannot measure %s of type %s", f.Name, v.Type())
+	}
+
+	if ok(n, limit) {
+		return "", nil
+	}
+	return fmt.Sprintf(messages[kind], f.Name, f.Arg), nil
+}
+
+func in(_ context.Context, f Field) (string, error) {
+	value := fmt.Sprint(f.Value.Interface())
+	for _, allowed := range strings.Split(f.Arg, "|") {
+		if value == allowed {
+			return "", nil
+		}
+	}
+	return fmt.Sprintf("The selected %s is invalid.", f.Name), nil
+}
+
+// regexCache keeps compiled expressions, tags being fixed per type
+var regexCache sync.Map
+
+func regex(_ context.Context, f Field) (string, error) {
+	cached, ok := regexCache.Load(f.Arg)
+	if !ok {
+		re, err := regexp.Compile(f.Arg)
+		if err != nil {
+			return "", fmt.Errorf("validation: invalid regex for %s: %w", f.Name, err)
+		}
+		cached, _ = regexCache.LoadOrStore(f.Arg, re)
+	}
+
+	if !cached.(*regexp.Regexp).MatchString(fmt.Sprint(f.Value.Interface())) {
+		return fmt.Sprintf("The %s field format is invalid.", f.Name), nil
+	}
+	return "", nil
+}
+
+func unique(ctx context.Context, f Field) (string, error) {
+	target, ignore, _ := strings.Cut(f.Arg, ":")
+	var except []string
+	if ignore != "" {
+		id, ok := Param(ctx, ignore)
+		if !ok {
+			return "", fmt.Errorf("validation: unique on %s ignores route parameter %q, which is missing", f.Name, ignore)
+		}
+		except = append(except, id)
+	}
+
+	count, err := countRows(ctx, f, target, except...)
+	if err != nil {
+		return "", err
+	}
+	if count > 0 {
+		return fmt.Sprintf("The %s has already been taken.", f.Name), nil
+	}
+	return "", nil
+}
+
+func exists(ctx context.Context, f Field) (string, error) {
+	count, err := countRows(ctx, f, f.Arg)
+	if err != nil {
+		return "", err
+	}
+	if count == 0 {
+		return fmt.Sprintf("The selected %s is invalid.", f.Name), nil
+	}
+	return "", nil
+}
+
+// identifier guards the table and column names spliced into queries
+var identifier = regexp.MustCompile(`^[A-Za-z_][A-Za-z0-9_]*$`)
+
+// countRows counts the rows of target, written table.column, equal to the
+// field value, leaving out the row whose id is except. The column defaults
+// to the field name.
+func countRows(ctx context.Context, f Field, target string, except ...string) (int, error) {
+	table, column, _ := strings.Cut(target, ".")
+	if column == "" {
+		column = f.Name
+	}
+	if !identifier.MatchString(table) || !identifier.MatchString(column) {
+		return 0, fmt.Errorf("validation: %s needs table.column, got %q", f.Name, target)
+	}
+
+	db := database.GetDB()
+	if db == nil {
+		return 0, fmt.Errorf("validation: checking %s needs a database connection", f.Name)
+	}
+
+	query := fmt.Sprintf("SELECT COUNT(*) FROM %s WHERE %s = %s", table, column, database.Placeholder(1))
+	args := []interface{}{f.Value.Interface()}
+	for _, id := range except {
+		args = append(args, id)
+		query += fmt.Sprintf(" AND id <> %s", database.Placeholder(len(args)))
+	}
+	var count int
+	if err := db.QueryRowContext(ctx, query, args...).Scan(&count); err != nil {
+		return 0, fmt.Errorf("validation: checking %s: %w", f.Name, err)
+	}
+	return count, nil
+}
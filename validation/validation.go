@@ -0,0 +1,258 @@
+// Package validation checks structs against rules in `validate` tags:
+//
+//	type CreateUser struct {
+//		Name  string `json:"name" validate:"required,max=100"`
+//		Email string `json:"email" validate:"required,email,unique=users.email"`
+//		Role  string `json:"role" validate:"in=admin|editor|viewer"`
+//		Team  int    `json:"team" validate:"exists=teams.id"`
+//		Code  string `json:"code" validate:"regex=^[A-Z]{3}$"`
+//	}
+//
+// Built-in rules are required, email, min=n, max=n, in=a|b, regex=expr,
+// unique=table.column and exists=table.column. unique=table.column:param
+// skips the row whose id is the route parameter param, so an update does
+// not collide with the row being updated. min and max compare
+// numbers by value and strings, slices and maps by length. Every rule but
+// required passes empty values. regex must come last in the tag, since
+// its expression may contain commas.
+package validation
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"reflect"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Errors maps field names to their failure messages
+type Errors map[string][]string
+
+// Add records a failure message for field
+func (errs Errors) Add(field, message string) {
+	errs[field] = append(errs[field], message)
+}
+
+func (errs Errors) Error() string {
+	fields := make([]string, 0, len(errs))
+	for field := range errs {
+		fields = append(fields, field)
+	}
+	sort.Strings(fields)
+
+	parts := make([]string, 0, len(fields))
+	for _, field := range fields {
+		parts = append(parts, strings.Join(errs[field], " "))
+	}
+	return "validation failed: " + strings.Join(parts, " ")
+}
+
+// StatusCode returns 422 Unprocessable Entity
+func (errs Errors) StatusCode() int {
+	return http.StatusUnprocessableEntity
+}
+
+// MarshalJSON renders the error bag as
+// {"message": "The given data was invalid.", "errors": {"field": [...]}}
+func (errs Errors) MarshalJSON() ([]byte, error) {
+	return json.Marshal(struct {
+		Message string              `json:"message"`
+		Errors  map[string][]string `json:"errors"`
+	}{"The given data was invalid.", errs})
+}
+
+// Write answers a plain http.HandlerFunc request with the error bag as
+// a 422 JSON response. Context handlers can return the error instead.
+func (errs Errors) Write(w http.ResponseWriter) {
+	body, _ := errs.MarshalJSON()
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(errs.StatusCode())
+	w.Write(append(body, '\n'))
+}
+
+// Field is the value a rule checks
+type Field struct {
+	Name  string        // name used in messages, from the json tag
+	Value reflect.Value // pointers already dereferenced
+	Arg   string        // text after "=" in the tag, e.g. "3" for min=3
+}
+
+// Rule checks one field. It returns a failure message, "" when the value
+// passes, or an error when the check itself could not run.
+type Rule func(ctx context.Context, f Field) (string, error)
+
+var (
+	rules   = map[string]Rule{}
+	rulesMu sync.RWMutex
+)
+
+// Register adds a rule usable in `validate` tags, replacing any rule of
+// the same name
+func Register(name string, rule Rule) {
+	rulesMu.Lock()
+	defer rulesMu.Unlock()
+	rules[name] = rule
+}
+
+// Validate checks v, a struct or pointer to struct. It returns Errors
+// when rules fail, another error when a rule could not run, or nil.
+func Validate(v interface{}) error {
+	return ValidateContext(context.Background(), v)
+}
+
+// ValidateContext is Validate with a context for the database rules.
+// Wrap it with WithParams for rules that refer to route parameters.
+func ValidateContext(ctx context.Context, v interface{}) error {
+	rv := reflect.ValueOf(v)
+	for rv.Kind() == reflect.Ptr && !rv.IsNil() {
+		rv = rv.Elem()
+	}
+	if rv.Kind() != reflect.Struct {
+		return fmt.Errorf("validation: cannot validate %T, expected a struct", v)
+	}
+
+	errs := Errors{}
+	if err := validateStruct(ctx, rv, "", errs); err != nil {
+		return err
+	}
+	if len(errs) == 0 {
+		return nil
+	}
+	return errs
+}
+
+type paramsKey struct{}
+
+// WithParams returns a copy of ctx carrying the route parameters
+func WithParams(ctx context.Context, params map[string]string) context.Context {
+	return context.WithValue(ctx, paramsKey{}, params)
+}
+
+// Param returns the route parameter name carried by ctx
+func Param(ctx context.Context, name string) (string, bool) {
+	params, _ := ctx.Value(paramsKey{}).(map[string]string)
+	value, ok := params[name]
+	return value, ok
+}
+
+func validateStruct(ctx context.Context, v reflect.Value, prefix string, errs Errors) error {
+	t := v.Type()
+	for i := 0; i < t.NumField(); i++ {
+		sf := t.Field(i)
+		if !sf.IsExported() {
+			continue
+		}
+
+		value := v.Field(i)
+		name := fieldName(sf)
+		if sf.Anonymous && sf.Tag.Get("json") == "" {
+			name = ""
+		}
+		if prefix != "" && name != "" {
+			name = prefix + "." + name
+		} else if name == "" {
+			name = prefix
+		}
+
+		if tag := sf.Tag.Get("validate"); tag != "" && tag != "-" {
+			if err := validateField(ctx, name, value, tag, errs); err != nil {
+				return err
+			}
+		}
+
+		// Nested structs are validated with dotted names, e.g. "address.city"
+		inner := indirect(value)
+		if inner.Kind() == reflect.Struct && inner.Type() != reflect.TypeOf(time.Time{}) {
+			if err := validateStruct(ctx, inner, name, errs); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+func validateField(ctx context.Context, name string, value reflect.Value, tag string, errs Errors) error {
+	for _, spec := range splitRules(tag) {
+		ruleName, arg, _ := strings.Cut(spec, "=")
+
+		rulesMu.RLock()
+		rule, ok := rules[ruleName]
+		rulesMu.RUnlock()
+		if !ok {
+			return fmt.Errorf("validation: unknown rule %q on field %s", ruleName, name)
+		}
+
+		if ruleName != "required" && isEmpty(value) {
+			continue
+		}
+		message, err := rule(ctx, Field{Name: name, Value: indirect(value), Arg: arg})
+		if err != nil {
+			return err
+		}
+		if message != "" {
+			errs.Add(name, message)
+			if ruleName == "required" {
+				return nil // the other rules would only repeat it
+			}
+		}
+	}
+	return nil
+}
+
+// splitRules splits a tag on commas, keeping everything after regex=
+// as the expression
+func splitRules(tag string) []string {
+	var specs []string
+	for tag != "" {
+		if strings.HasPrefix(tag, "regex=") {
+			return append(specs, tag)
+		}
+		spec, rest, _ := strings.Cut(tag, ",")
+		if spec = strings.TrimSpace(spec); spec != "" {
+			specs = append(specs, spec)
+		}
+		tag = strings.TrimSpace(rest)
+	}
+	return specs
+}
+
+// fieldName is the json name of a field, or its lower-cased Go name
+func fieldName(sf reflect.StructField) string {
+	if name := strings.Split(sf.Tag.Get("json"), ",")[0]; name != "" && name != "-" {
+		return name
+	}
+	return strings.ToLower(sf.Name)
+}
+
+func indirect(v reflect.Value) reflect.Value {
+	for v.Kind() == reflect.Ptr || v.Kind() == reflect.Interface {
+		if v.IsNil() {
+			return v
+		}
+		v = v.Elem()
+	}
+	return v
+}
+
+// isEmpty reports whether v holds no value: nil, blank text, an empty
+// collection or a zero time
+func isEmpty(v reflect.Value) bool {
+	v = indirect(v)
+	switch v.Kind() {
+	case reflect.Ptr, reflect.Interface:
+		return v.IsNil()
+	case reflect.String:
+		return strings.TrimSpace(v.String()) == ""
+	case reflect.Slice, reflect.Map, reflect.Array:
+		return v.Len() == 0
+	case reflect.Bool, reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64,
+		reflect.Float32, reflect.Float64:
+		return false // false and 0 are values, use a pointer to require one
+	}
+	return v.IsZero()
+}
@@ -2,29 +2,71 @@ package views
 
 import (
 	"html/template"
+	"io"
 	"log"
 	"net/http"
 	"path/filepath"
+	"sync"
 )
 
 // Template cache to avoid reloading templates for every request
-var templates = make(map[string]*template.Template)
+var (
+	templates = make(map[string]*template.Template)
+	funcs     = template.FuncMap{}
+	mu        sync.RWMutex
+)
+
+// Funcs makes extra functions available to every template, such as the
+// "route" helper from routes.Router.TemplateFuncs. Cached templates are
+// dropped so they are parsed again with the new functions.
+func Funcs(fm template.FuncMap) {
+	mu.Lock()
+	defer mu.Unlock()
+
+	for name, fn := range fm {
+		funcs[name] = fn
+	}
+	templates = make(map[string]*template.Template)
+}
+
+// ClearCache drops the cached templates, so edited files are parsed again
+// on their next use
+func ClearCache() {
+	mu.Lock()
+	defer mu.Unlock()
+	templates = make(map[string]*template.Template)
+}
 
 // Render function to process templates and send the response
 func Render(w http.ResponseWriter, tmpl string, data interface{}) {
+	if err := Execute(w, tmpl, data); err != nil {
+		log.Println("Error rendering template:", err)
+		http.Error(w, "Internal Server Error", http.StatusInternalServerError)
+	}
+}
+
+// Execute renders the template to out, loading and caching it on first
+// use. Unlike Render it leaves error handling to the caller.
+func Execute(out io.Writer, tmpl string, data interface{}) error {
+	mu.RLock()
+	t, ok := templates[tmpl]
+	mu.RUnlock()
+
 	// Check if the template is already loaded
-	if _, ok := templates[tmpl]; !ok {
+	if !ok {
+		mu.Lock()
 		// Load the template file from 'resources/views' directory
 		tmplPath := filepath.Join("resources", "views", tmpl+".html")
-		tmplParsed, err := template.ParseFiles(tmplPath)
+		tmplParsed, err := template.New(filepath.Base(tmplPath)).Funcs(funcs).ParseFiles(tmplPath)
 		if err != nil {
-			log.Println("Error loading template:", err)
-			http.Error(w, "Internal Server Error", http.StatusInternalServerError)
-			return
+			mu.Unlock()
+			return err
 		}
 		templates[tmpl] = tmplParsed
+		mu.Unlock()
+		t = tmplParsed
 	}
 
 	// Render the template with the data
-	templates[tmpl].Execute(w, data)
+	return t.Execute(out, data)
 }
@@ -3,13 +3,15 @@
 package main
 
 import (
+	"context"
 	"fmt"
 	"os"
 	"os/exec"
 	"os/signal"
-	"path/filepath"
 	"syscall"
-	"time"
+
+	"enzovu/config"
+	"enzovu/watcher"
 )
 
 func main() {
@@ -28,38 +30,37 @@ func main() {
 		os.Exit(0)
 	}()
 
-	lastMod := time.Now()
-
-	for {
-		// Check for Go file modifications
-		modified := false
-		filepath.Walk(".", func(path string, info os.FileInfo, err error) error {
-			if err != nil {
-				return nil
-			}
-			if filepath.Ext(path) == ".go" && info.ModTime().After(lastMod) {
-				modified = true
-				lastMod = time.Now()
-			}
-			return nil
-		})
-
-		if modified {
-			// Kill existing process if running!
-			if cmd != nil && cmd.Process != nil {
-				cmd.Process.Kill()
-				cmd.Wait()
-			}
-
-			fmt.Println("📝 Changes detected, restarting...")
+	cfg := config.GetConfig().Watch
+	files, err := watcher.New(watcher.Config{
+		Include:  cfg.Include,
+		Exclude:  cfg.Exclude,
+		Debounce: cfg.Debounce,
+		Poll:     cfg.Poll,
+	})
+	if err != nil {
+		fmt.Printf("❌ Cannot watch files: %v\n", err)
+		os.Exit(1)
+	}
 
-			// Start new process
-			cmd = exec.Command("go", "run", "main.go")
-			cmd.Stdout = os.Stdout
-			cmd.Stderr = os.Stderr
-			cmd.Start()
+	restart := func() {
+		// Kill existing process if running!
+		if cmd != nil && cmd.Process != nil {
+			cmd.Process.Kill()
+			cmd.Wait()
 		}
 
-		time.Sleep(500 * time.Millisecond)
+		// Start new process
+		cmd = exec.Command("go", "run", "main.go")
+		cmd.Stdout = os.Stdout
+		cmd.Stderr = os.Stderr
+		cmd.Start()
 	}
+
+	files.On(func(changed []string) {
+		fmt.Println("📝 Changes detected, restarting...")
+		restart()
+	}, "**/*.go")
+
+	restart()
+	files.Run(context.Background())
 }
@@ -0,0 +1,80 @@
+package watcher
+
+import (
+	"path"
+	"strings"
+)
+
+// Match reports whether the slash-separated path matches pattern.
+// Patterns use path.Match syntax per segment, plus "**" for any number of
+// directories. A pattern without a slash matches the file name in any
+// directory, so "*.go" is the same as "**/*.go".
+func Match(pattern, name string) bool {
+	if !strings.Contains(pattern, "/") {
+		pattern = "**/" + pattern
+	}
+	return matchSegments(strings.Split(pattern, "/"), strings.Split(name, "/"))
+}
+
+func matchSegments(pattern, name []string) bool {
+	for len(pattern) > 0 {
+		if pattern[0] == "**" {
+			// Try every split between what ** swallows and the rest
+			for i := 0; i <= len(name); i++ {
+				if matchSegments(pattern[1:], name[i:]) {
+					return true
+				}
+			}
+			return false
+		}
+		if len(name) == 0 {
+			return false
+		}
+		if ok, err := path.Match(pattern[0], name[0]); err != nil || !ok {
+			return false
+		}
+		pattern, name = pattern[1:], name[1:]
+	}
+	return len(name) == 0
+}
+
+// rules holds the include and exclude globs of a watcher
+type rules struct {
+	include []string
+	exclude []string
+}
+
+// file reports whether changes to the file at name are reported
+func (r rules) file(name string) bool {
+	for _, pattern := range r.exclude {
+		if Match(pattern, name) {
+			return false
+		}
+	}
+	for _, pattern := range r.include {
+		if Match(pattern, name) {
+			return true
+		}
+	}
+	return false
+}
+
+// dir reports whether the directory at name is watched. A directory is
+// skipped when an exclude pattern matches it, or everything below it as
+// "tmp/**" does. The prefix before "/**" is anchored at the root, so
+// "build/**" skips build but not cmd/build.
+func (r rules) dir(name string) bool {
+	if name == "." {
+		return true
+	}
+	for _, pattern := range r.exclude {
+		if Match(pattern, name) {
+			return false
+		}
+		if prefix, ok := strings.CutSuffix(pattern, "/**"); ok &&
+			matchSegments(strings.Split(prefix, "/"), strings.Split(name, "/")) {
+			return false
+		}
+	}
+	return true
+}
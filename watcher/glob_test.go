@@ -0,0 +1,88 @@
+package watcher
+
+import "testing"
+
+func TestMatch(t *testing.T) {
+	tests := []struct {
+		pattern string
+		name    string
+		want    bool
+	}{
+		{"go.mod", "go.mod", true},
+		{"go.mod", "vendor/x/go.mod", true}, // no slash: any directory
+		{"*.go", "main.go", true},
+		{"*.go", "app/Http/Controllers/HomeController.go", true},
+		{"*.go", "main.go.orig", false},
+		{"**/*.go", "main.go", true}, // ** may match no directory
+		{"**/*.go", "routes/web.go", true},
+		{"**/*.go", "a/b/c/d.go", true},
+		{"public/**", "public/css/app.css", true},
+		{"public/**", "public/app.js", true},
+		{"public/**", "publicity/app.js", false},
+		{"public/**", "src/public/app.js", false},
+		{"resources/views/**/*.html", "resources/views/home.html", true},
+		{"resources/views/**/*.html", "resources/views/users/show.html", true},
+		{"resources/views/**/*.html", "resources/views/users/show.txt", false},
+		{"resources/views/**/*.html", "resources/home.html", false},
+		{"a/**/b/**/c", "a/b/c", true},
+		{"a/**/b/**/c", "a/x/b/y/z/c", true},
+		{"a/**/b/**/c", "a/x/y/c", false},
+		{"**/*_test.go", "watcher/glob_test.go", true},
+		{"tmp/**", "tmp", true},
+		{"[", "[", false}, // malformed patterns match nothing
+	}
+	for _, tt := range tests {
+		if got := Match(tt.pattern, tt.name); got != tt.want {
+			t.Errorf("Match(%q, %q) = %t, want %t", tt.pattern, tt.name, got, tt.want)
+		}
+	}
+}
+
+func TestRules(t *testing.T) {
+	r := rules{
+		include: []string{"**/*.go", "go.mod", "go.sum", "public/**"},
+		exclude: []string{"tmp/**", "dist/**", "build/**", "**/*_test.go", "node_modules"},
+	}
+
+	files := []struct {
+		name string
+		want bool
+	}{
+		{"main.go", true},
+		{"go.sum", true},
+		{"public/css/app.css", true},
+		{"README.md", false},
+		{"tmp/main.go", false},
+		{"routes/tree_test.go", false}, // exclude wins over include
+		{"cmd/build/main.go", true},
+		{"app/dist/x.go", true},
+		{"build/main.go", false},
+	}
+	for _, tt := range files {
+		if got := r.file(tt.name); got != tt.want {
+			t.Errorf("file(%q) = %t, want %t", tt.name, got, tt.want)
+		}
+	}
+
+	dirs := []struct {
+		name string
+		want bool
+	}{
+		{".", true},
+		{"routes", true},
+		{"tmp", false}, // everything below it is excluded
+		{"tmp/cache", false},
+		{"cmd/build", true}, // "build/**" is anchored at the root
+		{"app/dist", true},
+		{"build", false},
+		{"dist/assets", false},
+		{"node_modules", false},
+		{"web/node_modules", false},
+		{"public", true},
+	}
+	for _, tt := range dirs {
+		if got := r.dir(tt.name); got != tt.want {
+			t.Errorf("dir(%q) = %t, want %t", tt.name, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,192 @@
+package watcher
+
+import (
+	"fmt"
+	"io/fs"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+	"sync"
+	"syscall"
+	"unsafe"
+)
+
+const inotifyMask = syscall.IN_CREATE | syscall.IN_CLOSE_WRITE | syscall.IN_MODIFY |
+	syscall.IN_DELETE | syscall.IN_MOVED_FROM | syscall.IN_MOVED_TO
+
+// inotify watches every included directory with one inotify instance
+type inotify struct {
+	root  string
+	rules rules
+	fd    int
+	file  *os.File // the inotify descriptor, registered with the runtime poller
+	out   chan Event
+
+	mu   sync.Mutex
+	dirs map[int32]string // watch descriptor to directory, relative to root
+}
+
+func newInotify(root string, r rules) (backend, error) {
+	// Non-blocking, so reads park the goroutine and close interrupts them
+	fd, err := syscall.InotifyInit1(syscall.IN_CLOEXEC | syscall.IN_NONBLOCK)
+	if err != nil {
+		return nil, fmt.Errorf("inotify_init: %w", err)
+	}
+	in := &inotify{
+		root:  root,
+		rules: r,
+		fd:    fd,
+		file:  os.NewFile(uintptr(fd), "inotify"),
+		out:   make(chan Event, 64),
+		dirs:  map[int32]string{},
+	}
+	if err := in.addTree(root, false); err != nil {
+		in.file.Close()
+		return nil, err
+	}
+	go in.read()
+	return in, nil
+}
+
+func (in *inotify) events() <-chan Event {
+	return in.out
+}
+
+func (in *inotify) close() error {
+	return in.file.Close()
+}
+
+// addTree watches dir and the directories below it. With report set, the
+// files found are reported as created, for directories that appeared
+// after watching started and may have filled before their watch was added.
+func (in *inotify) addTree(dir string, report bool) error {
+	return filepath.WalkDir(dir, func(name string, d fs.DirEntry, err error) error {
+		if err != nil {
+			if name == dir {
+				return err
+			}
+			return nil
+		}
+		rel := relative(in.root, name)
+		if !d.IsDir() {
+			if report && in.rules.file(rel) {
+				in.out <- Event{Path: rel, Op: Create}
+			}
+			return nil
+		}
+		if !in.rules.dir(rel) {
+			return filepath.SkipDir
+		}
+
+		wd, err := syscall.InotifyAddWatch(in.fd, name, inotifyMask)
+		if err != nil {
+			if err == syscall.ENOSPC {
+				return fmt.Errorf("out of inotify watches, raise fs.inotify.max_user_watches: %w", err)
+			}
+			return fmt.Errorf("watching %s: %w", name, err)
+		}
+		in.mu.Lock()
+		in.dirs[int32(wd)] = rel
+		in.mu.Unlock()
+		return nil
+	})
+}
+
+// removeTree drops the watches of a directory moved away, whose events
+// would otherwise carry its old path
+func (in *inotify) removeTree(rel string) {
+	in.mu.Lock()
+	defer in.mu.Unlock()
+	for wd, dir := range in.dirs {
+		if dir == rel || strings.HasPrefix(dir, rel+"/") {
+			syscall.InotifyRmWatch(in.fd, uint32(wd))
+			delete(in.dirs, wd)
+		}
+	}
+}
+
+func (in *inotify) read() {
+	defer close(in.out)
+
+	buf := make([]byte, 64*(syscall.SizeofInotifyEvent+syscall.NAME_MAX+1))
+	for {
+		n, err := in.file.Read(buf)
+		if err != nil {
+			return // closed
+		}
+		for offset := 0; offset+syscall.SizeofInotifyEvent <= n; {
+			raw := (*syscall.InotifyEvent)(unsafe.Pointer(&buf[offset]))
+			start := offset + syscall.SizeofInotifyEvent
+			name := strings.TrimRight(string(buf[start:start+int(raw.Len)]), "\x00")
+			offset = start + int(raw.Len)
+			in.handle(raw.Wd, raw.Mask, name)
+		}
+	}
+}
+
+func (in *inotify) handle(wd int32, mask uint32, name string) {
+	if mask&syscall.IN_Q_OVERFLOW != 0 {
+		in.rescan()
+		return
+	}
+
+	in.mu.Lock()
+	dir, ok := in.dirs[wd]
+	if mask&syscall.IN_IGNORED != 0 {
+		delete(in.dirs, wd)
+	}
+	in.mu.Unlock()
+	if !ok || name == "" {
+		return
+	}
+	rel := path.Join(dir, name)
+
+	if mask&syscall.IN_ISDIR != 0 {
+		switch {
+		case mask&(syscall.IN_CREATE|syscall.IN_MOVED_TO) != 0 && in.rules.dir(rel):
+			if err := in.addTree(filepath.Join(in.root, filepath.FromSlash(rel)), true); err != nil {
+				fmt.Printf("⚠️  %v\n", err)
+			}
+		case mask&syscall.IN_MOVED_FROM != 0:
+			in.removeTree(rel)
+		}
+		return
+	}
+	if !in.rules.file(rel) {
+		return
+	}
+
+	var op Op
+	switch {
+	case mask&(syscall.IN_CREATE|syscall.IN_MOVED_TO) != 0:
+		op = Create
+	case mask&(syscall.IN_DELETE|syscall.IN_MOVED_FROM) != 0:
+		op = Remove
+	case mask&(syscall.IN_CLOSE_WRITE|syscall.IN_MODIFY) != 0:
+		op = Write
+	default:
+		return
+	}
+	in.out <- Event{Path: rel, Op: op}
+}
+
+// rescan reports every file as written after the kernel dropped events
+func (in *inotify) rescan() {
+	filepath.WalkDir(in.root, func(name string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return nil
+		}
+		rel := relative(in.root, name)
+		if d.IsDir() {
+			if !in.rules.dir(rel) {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+		if in.rules.file(rel) {
+			in.out <- Event{Path: rel, Op: Write}
+		}
+		return nil
+	})
+}
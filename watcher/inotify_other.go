@@ -0,0 +1,9 @@
+//go:build !linux
+
+package watcher
+
+import "errors"
+
+func newInotify(root string, r rules) (backend, error) {
+	return nil, errors.New("inotify is only available on Linux")
+}
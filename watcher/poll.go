@@ -0,0 +1,100 @@
+package watcher
+
+import (
+	"io/fs"
+	"path/filepath"
+	"time"
+)
+
+// poller walks the tree on an interval and compares modification times
+type poller struct {
+	root  string
+	rules rules
+	out   chan Event
+	done  chan struct{}
+	files map[string]fileState
+}
+
+type fileState struct {
+	modTime time.Time
+	size    int64
+}
+
+func newPoller(root string, r rules, interval time.Duration) *poller {
+	p := &poller{root: root, rules: r, out: make(chan Event, 64), done: make(chan struct{})}
+	p.files = p.scan()
+	go p.run(interval)
+	return p
+}
+
+func (p *poller) events() <-chan Event {
+	return p.out
+}
+
+func (p *poller) close() error {
+	close(p.done)
+	return nil
+}
+
+func (p *poller) run(interval time.Duration) {
+	defer close(p.out)
+
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-p.done:
+			return
+		case <-ticker.C:
+		}
+
+		current := p.scan()
+		for path, state := range current {
+			previous, seen := p.files[path]
+			switch {
+			case !seen:
+				p.send(Event{Path: path, Op: Create})
+			case state != previous:
+				p.send(Event{Path: path, Op: Write})
+			}
+		}
+		for path := range p.files {
+			if _, ok := current[path]; !ok {
+				p.send(Event{Path: path, Op: Remove})
+			}
+		}
+		p.files = current
+	}
+}
+
+func (p *poller) send(e Event) {
+	select {
+	case p.out <- e:
+	case <-p.done:
+	}
+}
+
+// scan records every reported file below the root
+func (p *poller) scan() map[string]fileState {
+	files := map[string]fileState{}
+	filepath.WalkDir(p.root, func(name string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return nil
+		}
+		rel := relative(p.root, name)
+		if d.IsDir() {
+			if !p.rules.dir(rel) {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+		if !p.rules.file(rel) {
+			return nil
+		}
+		if info, err := d.Info(); err == nil {
+			files[rel] = fileState{modTime: info.ModTime(), size: info.Size()}
+		}
+		return nil
+	})
+	return files
+}
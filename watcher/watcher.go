@@ -0,0 +1,240 @@
+// Package watcher reports file changes below a directory, batched and
+// filtered by glob rules, and runs an action per kind of file:
+//
+//	w, err := watcher.New(watcher.Config{
+//		Root:    ".",
+//		Include: []string{"**/*.go", "go.mod", "go.sum", "resources/views/**/*.html"},
+//		Exclude: []string{".git/**", "tmp/**"},
+//	})
+//	w.On(func(changed []string) { rebuild() }, "**/*.go", "go.mod", "go.sum")
+//	w.On(func(changed []string) { views.ClearCache() }, "resources/views/**/*.html")
+//	w.Run(ctx)
+//
+// It uses inotify on Linux and falls back to walking the tree on an
+// interval elsewhere, or when inotify is unavailable or out of watches.
+package watcher
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+)
+
+// Op describes what happened to a file
+type Op uint8
+
+const (
+	Create Op = 1 << iota
+	Write
+	Remove
+)
+
+func (op Op) String() string {
+	var names []string
+	for _, o := range []struct {
+		op   Op
+		name string
+	}{{Create, "create"}, {Write, "write"}, {Remove, "remove"}} {
+		if op&o.op != 0 {
+			names = append(names, o.name)
+		}
+	}
+	return strings.Join(names, "|")
+}
+
+// Event is a change to one file. Path is relative to the root and uses
+// forward slashes.
+type Event struct {
+	Path string
+	Op   Op
+}
+
+// Defaults used for zero Config fields
+const (
+	DefaultDebounce     = 100 * time.Millisecond
+	DefaultPollInterval = 500 * time.Millisecond
+)
+
+// Config controls a Watcher
+type Config struct {
+	// Root is the directory to watch, "." when empty
+	Root string
+
+	// Include lists the globs of files to report, see Match
+	Include []string
+
+	// Exclude lists globs of files and directories to ignore. It wins
+	// over Include.
+	Exclude []string
+
+	// Debounce is the quiet time that ends a batch of changes,
+	// DefaultDebounce when zero
+	Debounce time.Duration
+
+	// Poll forces polling, e.g. for network or container file systems
+	// that do not deliver inotify events
+	Poll bool
+
+	// PollInterval is the polling period, DefaultPollInterval when zero
+	PollInterval time.Duration
+}
+
+// backend delivers raw events until it is closed
+type backend interface {
+	events() <-chan Event
+	close() error
+}
+
+// Watcher watches a directory tree, see the package comment
+type Watcher struct {
+	config  Config
+	backend backend
+	polling bool
+	actions []action
+}
+
+type action struct {
+	patterns []string
+	fn       func(changed []string)
+}
+
+// New starts watching config.Root
+func New(config Config) (*Watcher, error) {
+	if config.Root == "" {
+		config.Root = "."
+	}
+	if config.Debounce == 0 {
+		config.Debounce = DefaultDebounce
+	}
+	if config.PollInterval == 0 {
+		config.PollInterval = DefaultPollInterval
+	}
+	if info, err := os.Stat(config.Root); err != nil {
+		return nil, err
+	} else if !info.IsDir() {
+		return nil, fmt.Errorf("watcher: %s is not a directory", config.Root)
+	}
+
+	w := &Watcher{config: config}
+	r := rules{include: config.Include, exclude: config.Exclude}
+	if !config.Poll {
+		b, err := newInotify(config.Root, r)
+		if err == nil {
+			w.backend = b
+			return w, nil
+		}
+		fmt.Printf("⚠️  File events unavailable (%v), polling every %v\n", err, config.PollInterval)
+	}
+	w.backend = newPoller(config.Root, r, config.PollInterval)
+	w.polling = true
+	return w, nil
+}
+
+// Polling reports whether the watcher fell back to polling
+func (w *Watcher) Polling() bool {
+	return w.polling
+}
+
+// On runs fn with the changed paths matching any of patterns, once per
+// batch. Register actions before calling Run.
+func (w *Watcher) On(fn func(changed []string), patterns ...string) {
+	w.actions = append(w.actions, action{patterns: patterns, fn: fn})
+}
+
+// Run dispatches batches of changes to the actions until ctx is done,
+// then stops watching. Actions run one at a time; changes made meanwhile
+// form the next batch.
+func (w *Watcher) Run(ctx context.Context) error {
+	batches := make(chan []Event)
+	go debounce(w.backend.events(), batches, w.config.Debounce)
+	defer w.backend.close()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case batch, ok := <-batches:
+			if !ok {
+				return nil
+			}
+			w.dispatch(batch)
+		}
+	}
+}
+
+func (w *Watcher) dispatch(batch []Event) {
+	for _, a := range w.actions {
+		var changed []string
+		for _, e := range batch {
+			for _, pattern := range a.patterns {
+				if Match(pattern, e.Path) {
+					changed = append(changed, e.Path)
+					break
+				}
+			}
+		}
+		if len(changed) > 0 {
+			a.fn(changed)
+		}
+	}
+}
+
+// debounce merges raw events into batches that end after a quiet period.
+// It keeps collecting while the previous batch waits to be taken.
+func debounce(raw <-chan Event, out chan<- []Event, quiet time.Duration) {
+	defer close(out)
+
+	var pending, ready map[string]Op
+	var fire <-chan time.Time
+	var send chan<- []Event
+	var batch []Event
+
+	for {
+		select {
+		case e, ok := <-raw:
+			if !ok {
+				return
+			}
+			if pending == nil {
+				pending = map[string]Op{}
+			}
+			pending[e.Path] |= e.Op
+			fire = time.After(quiet)
+		case <-fire:
+			fire = nil
+			if ready == nil {
+				ready = map[string]Op{}
+			}
+			for path, op := range pending {
+				ready[path] |= op
+			}
+			pending = nil
+			batch, send = sorted(ready), out
+		case send <- batch:
+			ready, batch, send = nil, nil, nil
+		}
+	}
+}
+
+func sorted(ops map[string]Op) []Event {
+	events := make([]Event, 0, len(ops))
+	for path, op := range ops {
+		events = append(events, Event{Path: path, Op: op})
+	}
+	sort.Slice(events, func(i, j int) bool { return events[i].Path < events[j].Path })
+	return events
+}
+
+// relative converts a path below root to the slash-separated form used
+// in events and rules
+func relative(root, name string) string {
+	rel, err := filepath.Rel(root, name)
+	if err != nil {
+		return filepath.ToSlash(name)
+	}
+	return filepath.ToSlash(rel)
+}
@@ -0,0 +1,198 @@
+package watcher
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+	"time"
+)
+
+const quiet = 50 * time.Millisecond
+
+func startDebounce(t *testing.T) (chan<- Event, <-chan []Event) {
+	t.Helper()
+	raw := make(chan Event)
+	out := make(chan []Event)
+	go debounce(raw, out, quiet)
+	t.Cleanup(func() { close(raw) })
+	return raw, out
+}
+
+func receive(t *testing.T, out <-chan []Event) []Event {
+	t.Helper()
+	select {
+	case batch := <-out:
+		return batch
+	case <-time.After(2 * time.Second):
+		t.Fatal("no batch")
+		return nil
+	}
+}
+
+func expectNothing(t *testing.T, out <-chan []Event, wait time.Duration) {
+	t.Helper()
+	select {
+	case batch := <-out:
+		t.Fatalf("unexpected batch %v", batch)
+	case <-time.After(wait):
+	}
+}
+
+func TestDebounceMergesABurst(t *testing.T) {
+	raw, out := startDebounce(t)
+
+	raw <- Event{Path: "b.go", Op: Create}
+	raw <- Event{Path: "a.go", Op: Write}
+	raw <- Event{Path: "b.go", Op: Write}
+
+	want := []Event{{"a.go", Write}, {"b.go", Create | Write}}
+	if got := receive(t, out); !reflect.DeepEqual(got, want) {
+		t.Errorf("batch = %v, want %v", got, want)
+	}
+	expectNothing(t, out, 2*quiet)
+}
+
+func TestDebounceWaitsForQuiet(t *testing.T) {
+	raw, out := startDebounce(t)
+
+	// Each event comes before the quiet period ends, so one batch
+	// covers them all
+	var last time.Time
+	for i, name := range []string{"a.go", "b.go", "c.go", "d.go"} {
+		if i > 0 {
+			time.Sleep(quiet / 2)
+		}
+		raw <- Event{Path: name, Op: Write}
+		last = time.Now()
+	}
+	batch := receive(t, out)
+	if len(batch) != 4 {
+		t.Errorf("batch = %v, want all four files", batch)
+	}
+	if settled := time.Since(last); settled < quiet {
+		t.Errorf("batch %v after the last event, want it after the quiet period of %v", settled, quiet)
+	}
+}
+
+func TestDebounceCollectsWhileBusy(t *testing.T) {
+	raw, out := startDebounce(t)
+
+	// Nobody takes the first batch, so the second one joins it
+	raw <- Event{Path: "a.go", Op: Write}
+	time.Sleep(3 * quiet)
+	raw <- Event{Path: "b.go", Op: Create}
+	time.Sleep(3 * quiet)
+
+	want := []Event{{"a.go", Write}, {"b.go", Create}}
+	if got := receive(t, out); !reflect.DeepEqual(got, want) {
+		t.Errorf("batch = %v, want %v", got, want)
+	}
+}
+
+func TestDebounceSeparatesBatches(t *testing.T) {
+	raw, out := startDebounce(t)
+
+	raw <- Event{Path: "a.go", Op: Write}
+	if got := receive(t, out); len(got) != 1 || got[0].Path != "a.go" {
+		t.Fatalf("first batch = %v, want a.go", got)
+	}
+	raw <- Event{Path: "b.go", Op: Write}
+	if got := receive(t, out); len(got) != 1 || got[0].Path != "b.go" {
+		t.Fatalf("second batch = %v, want b.go", got)
+	}
+}
+
+func TestDebounceClosesWithItsInput(t *testing.T) {
+	raw := make(chan Event)
+	out := make(chan []Event)
+	go debounce(raw, out, quiet)
+	close(raw)
+
+	select {
+	case _, ok := <-out:
+		if ok {
+			t.Error("got a batch, want the output closed")
+		}
+	case <-time.After(time.Second):
+		t.Error("output still open after the input closed")
+	}
+}
+
+func TestWatcherRunsMatchingActions(t *testing.T) {
+	root := t.TempDir()
+	write := func(name, content string) {
+		t.Helper()
+		path := filepath.Join(root, filepath.FromSlash(name))
+		if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(path, []byte(content), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	write("main.go", "package main")
+	write("resources/views/home.html", "<p>home</p>")
+
+	for _, poll := range []bool{false, true} {
+		name := "events"
+		if poll {
+			name = "polling"
+		}
+		t.Run(name, func(t *testing.T) {
+			w, err := New(Config{
+				Root:         root,
+				Include:      []string{"**/*.go", "go.sum", "resources/views/**/*.html"},
+				Exclude:      []string{"tmp/**"},
+				Debounce:     quiet,
+				Poll:         poll,
+				PollInterval: 20 * time.Millisecond,
+			})
+			if err != nil {
+				t.Fatal(err)
+			}
+			code := make(chan []string, 4)
+			views := make(chan []string, 4)
+			w.On(func(changed []string) { code <- changed }, "**/*.go", "go.sum")
+			w.On(func(changed []string) { views <- changed }, "resources/views/**/*.html")
+
+			ctx, cancel := context.WithCancel(context.Background())
+			defer cancel()
+			go w.Run(ctx)
+			time.Sleep(50 * time.Millisecond) // let polling take its first look
+
+			write("main.go", "package main // changed")
+			write("go.sum", "example.com/x v1.0.0 h1:abc=")
+			write("tmp/build.go", "package tmp") // excluded
+			write("notes.txt", "not watched")    // not included
+			write("resources/views/users/show.html", "<p>user</p>")
+
+			want := map[string]bool{"go.sum": true, "main.go": true}
+			got := map[string]bool{}
+			deadline := time.After(3 * time.Second)
+			for len(got) < len(want) {
+				select {
+				case changed := <-code:
+					for _, name := range changed {
+						got[name] = true
+					}
+				case <-deadline:
+					t.Fatalf("code action saw %v, want %v", got, want)
+				}
+			}
+			if !reflect.DeepEqual(got, want) {
+				t.Errorf("code action saw %v, want %v", got, want)
+			}
+
+			select {
+			case changed := <-views:
+				if !reflect.DeepEqual(changed, []string{"resources/views/users/show.html"}) {
+					t.Errorf("views action saw %v", changed)
+				}
+			case <-time.After(3 * time.Second):
+				t.Error("views action did not run")
+			}
+		})
+	}
+}
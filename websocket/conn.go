@@ -0,0 +1,448 @@
+package websocket
+
+import (
+	"bufio"
+	"crypto/rand"
+	"encoding/binary"
+	"encoding/json"
+	"errors"
+	"io"
+	"net"
+	"net/http"
+	"sync"
+	"sync/atomic"
+	"time"
+	"unicode/utf8"
+)
+
+// Frame opcodes
+const (
+	opContinuation = 0x0
+	opText         = 0x1
+	opBinary       = 0x2
+	opClose        = 0x8
+	opPing         = 0x9
+	opPong         = 0xA
+)
+
+// closeTimeout bounds the wait for the peer's reply to a close frame
+const closeTimeout = 5 * time.Second
+
+// Conn is an open WebSocket connection. One goroutine may read while
+// others write; writes are serialized.
+type Conn struct {
+	conn        net.Conn
+	br          *bufio.Reader
+	req         *http.Request
+	client      bool
+	subprotocol string
+	config      Config
+
+	readMu    sync.Mutex
+	readLimit atomic.Int64
+	readErr   error
+	lastRead  atomic.Int64 // unix nanoseconds of the last frame received
+
+	writeMu   sync.Mutex
+	closeSent bool
+
+	closeReceived chan struct{}
+	closeOnce     sync.Once
+	done          chan struct{}
+	doneOnce      sync.Once
+}
+
+func newConn(netConn net.Conn, br *bufio.Reader, req *http.Request, client bool, subprotocol string, cfg Config) *Conn {
+	c := &Conn{
+		conn:          netConn,
+		br:            br,
+		req:           req,
+		client:        client,
+		subprotocol:   subprotocol,
+		config:        cfg,
+		closeReceived: make(chan struct{}),
+		done:          make(chan struct{}),
+	}
+	c.readLimit.Store(cfg.ReadLimit)
+	c.lastRead.Store(time.Now().UnixNano())
+	if cfg.PingInterval > 0 {
+		go c.keepAlive(cfg.PingInterval)
+	}
+	return c
+}
+
+// Request returns the handshake request, which carries the route
+// parameters and any values set by middleware
+func (c *Conn) Request() *http.Request {
+	return c.req
+}
+
+// Subprotocol returns the protocol agreed in the handshake, or ""
+func (c *Conn) Subprotocol() string {
+	return c.subprotocol
+}
+
+// RemoteAddr returns the peer's network address
+func (c *Conn) RemoteAddr() net.Addr {
+	return c.conn.RemoteAddr()
+}
+
+// SetReadLimit changes the maximum message size, DefaultReadLimit when
+// zero or negative
+func (c *Conn) SetReadLimit(limit int64) {
+	c.readLimit.Store(limit)
+}
+
+// maxMessage is the read limit in effect. There is always one, since the
+// payload is allocated at the length the peer announces.
+func (c *Conn) maxMessage() int64 {
+	if limit := c.readLimit.Load(); limit > 0 {
+		return limit
+	}
+	return DefaultReadLimit
+}
+
+// Done is closed once the underlying connection is closed
+func (c *Conn) Done() <-chan struct{} {
+	return c.done
+}
+
+// ReadMessage returns the next text or binary message. Once the peer
+// closes the connection it returns a *CloseError, and keeps returning it.
+func (c *Conn) ReadMessage() (int, []byte, error) {
+	c.readMu.Lock()
+	defer c.readMu.Unlock()
+	return c.readMessage()
+}
+
+// ReadJSON reads the next message and decodes it into v
+func (c *Conn) ReadJSON(v interface{}) error {
+	_, data, err := c.ReadMessage()
+	if err != nil {
+		return err
+	}
+	return json.Unmarshal(data, v)
+}
+
+func (c *Conn) readMessage() (int, []byte, error) {
+	if c.readErr != nil {
+		return 0, nil, c.readErr
+	}
+
+	var typ int
+	var message []byte
+	for {
+		fin, op, payload, err := c.readFrame(int64(len(message)))
+		if err != nil {
+			return 0, nil, c.readFailed(err)
+		}
+		c.lastRead.Store(time.Now().UnixNano())
+
+		switch op {
+		case opPing:
+			if err := c.writeFrame(opPong, payload); err != nil && err != ErrClosed {
+				return 0, nil, c.readFailed(err)
+			}
+			continue
+		case opPong:
+			continue
+		case opClose:
+			return 0, nil, c.peerClosed(payload)
+		case opText, opBinary:
+			if typ != 0 {
+				return 0, nil, c.readFailed(&CloseError{CloseProtocolError, "message started before the previous one ended"})
+			}
+			typ, message = op, payload
+		case opContinuation:
+			if typ == 0 {
+				return 0, nil, c.readFailed(&CloseError{CloseProtocolError, "continuation without a message"})
+			}
+			message = append(message, payload...)
+		default:
+			return 0, nil, c.readFailed(&CloseError{CloseProtocolError, "unknown opcode"})
+		}
+
+		if fin {
+			if typ == TextMessage && !utf8.Valid(message) {
+				return 0, nil, c.readFailed(&CloseError{CloseInvalidPayload, "text message is not valid UTF-8"})
+			}
+			return typ, message, nil
+		}
+	}
+}
+
+// readFrame reads one frame. read is the size of the message so far,
+// for the read limit.
+func (c *Conn) readFrame(read int64) (fin bool, op int, payload []byte, err error) {
+	var header [2]byte
+	if _, err := io.ReadFull(c.br, header[:]); err != nil {
+		return false, 0, nil, err
+	}
+	fin = header[0]&0x80 != 0
+	op = int(header[0] & 0x0f)
+	if header[0]&0x70 != 0 {
+		return false, 0, nil, &CloseError{CloseProtocolError, "reserved bits set"}
+	}
+	// Clients mask every frame, servers none
+	if masked := header[1]&0x80 != 0; masked == c.client {
+		return false, 0, nil, &CloseError{CloseProtocolError, "wrong frame masking"}
+	}
+
+	length := int64(header[1] & 0x7f)
+	switch length {
+	case 126:
+		var ext [2]byte
+		if _, err := io.ReadFull(c.br, ext[:]); err != nil {
+			return false, 0, nil, err
+		}
+		length = int64(binary.BigEndian.Uint16(ext[:]))
+	case 127:
+		var ext [8]byte
+		if _, err := io.ReadFull(c.br, ext[:]); err != nil {
+			return false, 0, nil, err
+		}
+		if ext[0]&0x80 != 0 {
+			return false, 0, nil, &CloseError{CloseProtocolError, "invalid frame length"}
+		}
+		length = int64(binary.BigEndian.Uint64(ext[:]))
+	}
+
+	if op >= opClose {
+		if !fin || length > 125 {
+			return false, 0, nil, &CloseError{CloseProtocolError, "invalid control frame"}
+		}
+	} else if read+length > c.maxMessage() {
+		return false, 0, nil, &CloseError{CloseMessageTooBig, "message exceeds the read limit"}
+	}
+
+	var mask [4]byte
+	if !c.client {
+		if _, err := io.ReadFull(c.br, mask[:]); err != nil {
+			return false, 0, nil, err
+		}
+	}
+	payload = make([]byte, length)
+	if _, err := io.ReadFull(c.br, payload); err != nil {
+		return false, 0, nil, err
+	}
+	if !c.client {
+		maskBytes(mask, payload)
+	}
+	return fin, op, payload, nil
+}
+
+// readFailed ends the connection after a read error. Protocol violations
+// are reported to the peer with a close frame first.
+func (c *Conn) readFailed(err error) error {
+	var closeErr *CloseError
+	switch {
+	case errors.As(err, &closeErr):
+		c.writeClose(closeErr.Code, closeErr.Reason)
+	case errors.Is(err, net.ErrClosed):
+		err = ErrClosed
+	case errors.Is(err, io.EOF), errors.Is(err, io.ErrUnexpectedEOF):
+		err = &CloseError{Code: CloseAbnormalClosure, Reason: "unexpected EOF"}
+	}
+	c.readErr = err
+	c.closeConn()
+	return err
+}
+
+// peerClosed answers the peer's close frame and ends the connection
+func (c *Conn) peerClosed(payload []byte) error {
+	closeErr := &CloseError{Code: CloseNoStatusReceived}
+	if len(payload) >= 2 {
+		closeErr.Code = int(binary.BigEndian.Uint16(payload))
+		closeErr.Reason = string(payload[2:])
+		if !validCloseCode(closeErr.Code) || !utf8.ValidString(closeErr.Reason) {
+			return c.readFailed(&CloseError{CloseProtocolError, "invalid close frame"})
+		}
+	} else if len(payload) == 1 {
+		return c.readFailed(&CloseError{CloseProtocolError, "invalid close frame"})
+	}
+
+	c.closeOnce.Do(func() { close(c.closeReceived) })
+	echo := closeErr.Code
+	if echo == CloseNoStatusReceived {
+		echo = CloseNormalClosure
+	}
+	c.writeClose(echo, "")
+	c.readErr = closeErr
+	c.closeConn()
+	return closeErr
+}
+
+func validCloseCode(code int) bool {
+	switch {
+	case code >= 3000 && code <= 4999:
+		return true
+	case code < 1000 || code > 1014:
+		return false
+	}
+	return code != 1004 && code != CloseNoStatusReceived && code != CloseAbnormalClosure
+}
+
+// WriteMessage sends a text or binary message
+func (c *Conn) WriteMessage(typ int, data []byte) error {
+	if typ != TextMessage && typ != BinaryMessage {
+		return errors.New("websocket: message type must be TextMessage or BinaryMessage")
+	}
+	if typ == TextMessage && !utf8.Valid(data) {
+		return errors.New("websocket: text message is not valid UTF-8")
+	}
+	return c.writeFrame(typ, data)
+}
+
+// WriteText sends a text message
+func (c *Conn) WriteText(text string) error {
+	return c.WriteMessage(TextMessage, []byte(text))
+}
+
+// WriteJSON sends v encoded as a JSON text message
+func (c *Conn) WriteJSON(v interface{}) error {
+	data, err := json.Marshal(v)
+	if err != nil {
+		return err
+	}
+	return c.WriteMessage(TextMessage, data)
+}
+
+// Ping sends a ping frame. The pong is consumed by ReadMessage.
+func (c *Conn) Ping(data []byte) error {
+	if len(data) > 125 {
+		return errors.New("websocket: ping payload exceeds 125 bytes")
+	}
+	return c.writeFrame(opPing, data)
+}
+
+func (c *Conn) writeFrame(op int, payload []byte) error {
+	c.writeMu.Lock()
+	defer c.writeMu.Unlock()
+	if c.closeSent {
+		return ErrClosed
+	}
+	if op == opClose {
+		c.closeSent = true
+	}
+
+	frame := make([]byte, 0, 14+len(payload))
+	frame = append(frame, 0x80|byte(op))
+	var maskBit byte
+	if c.client {
+		maskBit = 0x80
+	}
+	switch n := len(payload); {
+	case n <= 125:
+		frame = append(frame, maskBit|byte(n))
+	case n <= 0xffff:
+		frame = append(frame, maskBit|126)
+		frame = binary.BigEndian.AppendUint16(frame, uint16(n))
+	default:
+		frame = append(frame, maskBit|127)
+		frame = binary.BigEndian.AppendUint64(frame, uint64(n))
+	}
+
+	if c.client {
+		var mask [4]byte
+		if _, err := rand.Read(mask[:]); err != nil {
+			return err
+		}
+		frame = append(frame, mask[:]...)
+		start := len(frame)
+		frame = append(frame, payload...)
+		maskBytes(mask, frame[start:])
+	} else {
+		frame = append(frame, payload...)
+	}
+
+	c.conn.SetWriteDeadline(time.Now().Add(c.config.WriteTimeout))
+	if _, err := c.conn.Write(frame); err != nil {
+		if errors.Is(err, net.ErrClosed) {
+			return ErrClosed
+		}
+		return err
+	}
+	return nil
+}
+
+// writeClose sends a close frame, once
+func (c *Conn) writeClose(code int, reason string) error {
+	if len(reason) > 123 {
+		reason = reason[:123]
+	}
+	payload := binary.BigEndian.AppendUint16(nil, uint16(code))
+	return c.writeFrame(opClose, append(payload, reason...))
+}
+
+// Close closes the connection normally, see CloseWithReason
+func (c *Conn) Close() error {
+	return c.CloseWithReason(CloseNormalClosure, "")
+}
+
+// CloseWithReason sends a close frame, waits briefly for the peer to
+// answer it and closes the connection. Closing a closed connection does
+// nothing.
+func (c *Conn) CloseWithReason(code int, reason string) error {
+	if err := c.writeClose(code, reason); err != nil {
+		c.closeConn()
+		return nil
+	}
+
+	// Another goroutine reading will see the peer's close frame; without
+	// one, read it here
+	if c.readMu.TryLock() {
+		c.conn.SetReadDeadline(time.Now().Add(closeTimeout))
+		for {
+			if _, _, err := c.readMessage(); err != nil {
+				break
+			}
+		}
+		c.readMu.Unlock()
+	} else {
+		select {
+		case <-c.closeReceived:
+		case <-c.done:
+		case <-time.After(closeTimeout):
+		}
+	}
+	c.closeConn()
+	return nil
+}
+
+func (c *Conn) closeConn() {
+	c.doneOnce.Do(func() {
+		c.conn.Close()
+		close(c.done)
+	})
+}
+
+// keepAlive pings the peer and drops the connection when a reader has
+// received nothing for two intervals. Pongs are only seen while reading,
+// so the time without a reader does not count.
+func (c *Conn) keepAlive(interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ticker.C:
+			if c.readMu.TryLock() {
+				c.lastRead.Store(time.Now().UnixNano())
+				c.readMu.Unlock()
+			} else if time.Since(time.Unix(0, c.lastRead.Load())) > 2*interval {
+				c.closeConn()
+				return
+			}
+			if err := c.writeFrame(opPing, nil); err != nil {
+				return
+			}
+		case <-c.done:
+			return
+		}
+	}
+}
+
+func maskBytes(mask [4]byte, b []byte) {
+	for i := range b {
+		b[i] ^= mask[i&3]
+	}
+}
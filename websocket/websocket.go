@@ -0,0 +1,137 @@
+// Package websocket implements RFC 6455 WebSocket connections, both the
+// server upgrade and a small client for tests and tools:
+//
+//	router.WebSocket("/rooms/{room}", func(conn *websocket.Conn) {
+//		room := routes.GetParam(conn.Request(), "room")
+//		for {
+//			typ, msg, err := conn.ReadMessage()
+//			if err != nil {
+//				return // closed by the client, or a protocol error
+//			}
+//			conn.WriteMessage(typ, msg)
+//		}
+//	})
+//
+// Control frames are handled while reading: pings are answered, and a
+// close from the peer is echoed and ends the connection. Idle connections
+// are pinged, and dropped when the peer stops answering while they are
+// being read.
+package websocket
+
+import (
+	"crypto/tls"
+	"errors"
+	"fmt"
+	"net/http"
+	"time"
+)
+
+// Message types, as passed to WriteMessage and returned by ReadMessage
+const (
+	TextMessage   = 1
+	BinaryMessage = 2
+)
+
+// Close codes from RFC 6455 section 7.4.1
+const (
+	CloseNormalClosure    = 1000
+	CloseGoingAway        = 1001
+	CloseProtocolError    = 1002
+	CloseUnsupportedData  = 1003
+	CloseNoStatusReceived = 1005
+	CloseAbnormalClosure  = 1006
+	CloseInvalidPayload   = 1007
+	ClosePolicyViolation  = 1008
+	CloseMessageTooBig    = 1009
+	CloseInternalError    = 1011
+)
+
+// Defaults used for zero Config fields
+const (
+	DefaultReadLimit    = 1 << 20
+	DefaultPingInterval = 30 * time.Second
+	DefaultWriteTimeout = 10 * time.Second
+)
+
+// ErrClosed is returned when using a connection after it was closed
+var ErrClosed = errors.New("websocket: connection closed")
+
+// CloseError ends a connection: the close frame sent by the peer, or the
+// one sent after a protocol violation. Code is CloseAbnormalClosure when
+// the connection dropped without a close frame.
+type CloseError struct {
+	Code   int
+	Reason string
+}
+
+func (e *CloseError) Error() string {
+	if e.Reason == "" {
+		return fmt.Sprintf("websocket: close %d", e.Code)
+	}
+	return fmt.Sprintf("websocket: close %d: %s", e.Code, e.Reason)
+}
+
+// HandshakeError is returned when a request cannot be upgraded. Upgrade
+// has already answered it with Status.
+type HandshakeError struct {
+	Status  int
+	Message string
+}
+
+func (e *HandshakeError) Error() string {
+	return "websocket: " + e.Message
+}
+
+// Config tunes a connection
+type Config struct {
+	// Subprotocols lists the supported protocols in order of preference.
+	// Dial offers them, Upgrade picks the first one the client offers.
+	Subprotocols []string
+
+	// CheckOrigin accepts or rejects the request's Origin. By default
+	// browsers may only connect from the same host.
+	CheckOrigin func(r *http.Request) bool
+
+	// ReadLimit caps the size of a message, DefaultReadLimit when zero or
+	// negative. Larger messages close the connection with
+	// CloseMessageTooBig.
+	ReadLimit int64
+
+	// PingInterval is how often the connection is pinged,
+	// DefaultPingInterval when zero and never when negative. A peer that
+	// sends nothing for two intervals while the connection is being read
+	// is disconnected.
+	PingInterval time.Duration
+
+	// WriteTimeout bounds every write, DefaultWriteTimeout when zero
+	WriteTimeout time.Duration
+
+	// TLSConfig is used by Dial for wss:// URLs
+	TLSConfig *tls.Config
+}
+
+func (c Config) withDefaults() Config {
+	if c.ReadLimit == 0 {
+		c.ReadLimit = DefaultReadLimit
+	}
+	if c.PingInterval == 0 {
+		c.PingInterval = DefaultPingInterval
+	}
+	if c.WriteTimeout == 0 {
+		c.WriteTimeout = DefaultWriteTimeout
+	}
+	return c
+}
+
+// Handler adapts fn to an http.HandlerFunc that upgrades the request,
+// runs fn and closes the connection when fn returns
+func Handler(fn func(conn *Conn), config ...Config) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		conn, err := Upgrade(w, r, config...)
+		if err != nil {
+			return // Upgrade answered the request
+		}
+		defer conn.Close()
+		fn(conn)
+	}
+}
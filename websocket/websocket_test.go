@@ -0,0 +1,360 @@
+package websocket
+
+import (
+	"bytes"
+	"context"
+	"encoding/binary"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+// echoServer serves a connection echoing every message until it fails,
+// then reports the error that ended it
+func echoServer(t *testing.T, cfg Config) (*httptest.Server, <-chan error) {
+	t.Helper()
+	ended := make(chan error, 1)
+	server := httptest.NewServer(Handler(func(conn *Conn) {
+		for {
+			typ, msg, err := conn.ReadMessage()
+			if err != nil {
+				ended <- err
+				return
+			}
+			if err := conn.WriteMessage(typ, msg); err != nil {
+				ended <- err
+				return
+			}
+		}
+	}, cfg))
+	t.Cleanup(server.Close)
+	return server, ended
+}
+
+func dial(t *testing.T, url string, header http.Header, cfg Config) *Conn {
+	t.Helper()
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	conn, _, err := Dial(ctx, url, header, cfg)
+	if err != nil {
+		t.Fatalf("Dial: %v", err)
+	}
+	t.Cleanup(func() { conn.closeConn() })
+	return conn
+}
+
+// writeRaw sends a single masked client frame, fin and opcode as given
+func writeRaw(t *testing.T, c *Conn, fin bool, op int, payload []byte) {
+	t.Helper()
+	first := byte(op)
+	if fin {
+		first |= 0x80
+	}
+	frame := []byte{first}
+	switch n := len(payload); {
+	case n <= 125:
+		frame = append(frame, 0x80|byte(n))
+	case n <= 0xffff:
+		frame = binary.BigEndian.AppendUint16(append(frame, 0x80|126), uint16(n))
+	default:
+		frame = binary.BigEndian.AppendUint64(append(frame, 0x80|127), uint64(n))
+	}
+	mask := [4]byte{1, 2, 3, 4}
+	masked := append([]byte(nil), payload...)
+	maskBytes(mask, masked)
+	frame = append(append(frame, mask[:]...), masked...)
+	if _, err := c.conn.Write(frame); err != nil {
+		t.Fatalf("writing frame: %v", err)
+	}
+}
+
+func wantClose(t *testing.T, err error, code int) *CloseError {
+	t.Helper()
+	var closeErr *CloseError
+	if !errors.As(err, &closeErr) {
+		t.Fatalf("error = %v, want a close %d", err, code)
+	}
+	if closeErr.Code != code {
+		t.Fatalf("close code = %d (%q), want %d", closeErr.Code, closeErr.Reason, code)
+	}
+	return closeErr
+}
+
+func TestHandshake(t *testing.T) {
+	server, _ := echoServer(t, Config{Subprotocols: []string{"chat.v2", "chat"}})
+
+	t.Run("upgrade", func(t *testing.T) {
+		ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+		defer cancel()
+		conn, resp, err := Dial(ctx, server.URL, nil, Config{Subprotocols: []string{"chat", "chat.v2"}})
+		if err != nil {
+			t.Fatalf("Dial: %v", err)
+		}
+		defer conn.Close()
+		if resp.StatusCode != http.StatusSwitchingProtocols {
+			t.Errorf("status = %d, want 101", resp.StatusCode)
+		}
+		// The first protocol the client offers that the server supports
+		if conn.Subprotocol() != "chat" {
+			t.Errorf("subprotocol = %q, want chat", conn.Subprotocol())
+		}
+	})
+
+	tests := []struct {
+		name   string
+		method string
+		header map[string]string
+		status int
+	}{
+		{"not an upgrade", http.MethodGet, nil, http.StatusBadRequest},
+		{"wrong method", http.MethodPost, map[string]string{"Connection": "Upgrade", "Upgrade": "websocket"}, http.StatusMethodNotAllowed},
+		{"old version", http.MethodGet, map[string]string{
+			"Connection": "Upgrade", "Upgrade": "websocket", "Sec-WebSocket-Version": "8",
+			"Sec-WebSocket-Key": "dGhlIHNhbXBsZSBub25jZQ==",
+		}, http.StatusUpgradeRequired},
+		{"bad key", http.MethodGet, map[string]string{
+			"Connection": "Upgrade", "Upgrade": "websocket", "Sec-WebSocket-Version": "13",
+			"Sec-WebSocket-Key": "short",
+		}, http.StatusBadRequest},
+		{"foreign origin", http.MethodGet, map[string]string{
+			"Connection": "Upgrade", "Upgrade": "websocket", "Sec-WebSocket-Version": "13",
+			"Sec-WebSocket-Key": "dGhlIHNhbXBsZSBub25jZQ==", "Origin": "https://evil.example",
+		}, http.StatusForbidden},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req, _ := http.NewRequest(tt.method, server.URL, nil)
+			for name, value := range tt.header {
+				req.Header.Set(name, value)
+			}
+			resp, err := http.DefaultClient.Do(req)
+			if err != nil {
+				t.Fatal(err)
+			}
+			resp.Body.Close()
+			if resp.StatusCode != tt.status {
+				t.Errorf("status = %d, want %d", resp.StatusCode, tt.status)
+			}
+		})
+	}
+}
+
+func TestEcho(t *testing.T) {
+	server, _ := echoServer(t, Config{})
+	conn := dial(t, server.URL, nil, Config{})
+
+	messages := []struct {
+		name string
+		typ  int
+		data []byte
+	}{
+		{"text", TextMessage, []byte("hello")},
+		{"binary", BinaryMessage, []byte{0, 1, 2, 255}},
+		{"16-bit length", BinaryMessage, bytes.Repeat([]byte("a"), 1000)},
+		{"64-bit length", TextMessage, bytes.Repeat([]byte("b"), 70000)},
+		{"empty", TextMessage, []byte{}},
+	}
+	for _, m := range messages {
+		t.Run(m.name, func(t *testing.T) {
+			if err := conn.WriteMessage(m.typ, m.data); err != nil {
+				t.Fatalf("WriteMessage: %v", err)
+			}
+			typ, data, err := conn.ReadMessage()
+			if err != nil {
+				t.Fatalf("ReadMessage: %v", err)
+			}
+			if typ != m.typ || !bytes.Equal(data, m.data) {
+				t.Errorf("echo = type %d, %d bytes; want type %d, %d bytes", typ, len(data), m.typ, len(m.data))
+			}
+		})
+	}
+}
+
+func TestFragmentedMessage(t *testing.T) {
+	t.Run("reassembled with a ping in between", func(t *testing.T) {
+		server, _ := echoServer(t, Config{})
+		conn := dial(t, server.URL, nil, Config{})
+		writeRaw(t, conn, false, opText, []byte("Hel"))
+		writeRaw(t, conn, true, opPing, []byte("mid"))
+		writeRaw(t, conn, false, opContinuation, []byte("lo, "))
+		writeRaw(t, conn, true, opContinuation, []byte("world"))
+
+		typ, data, err := conn.ReadMessage()
+		if err != nil {
+			t.Fatalf("ReadMessage: %v", err)
+		}
+		if typ != TextMessage || string(data) != "Hello, world" {
+			t.Errorf("echo = %d %q, want text %q", typ, data, "Hello, world")
+		}
+	})
+
+	t.Run("continuation without a message", func(t *testing.T) {
+		server, ended := echoServer(t, Config{})
+		conn := dial(t, server.URL, nil, Config{})
+		writeRaw(t, conn, true, opContinuation, []byte("orphan"))
+		_, _, err := conn.ReadMessage()
+		wantClose(t, err, CloseProtocolError)
+		wantClose(t, <-ended, CloseProtocolError)
+	})
+
+	t.Run("new message before the last ended", func(t *testing.T) {
+		server, ended := echoServer(t, Config{})
+		conn := dial(t, server.URL, nil, Config{})
+		writeRaw(t, conn, false, opText, []byte("one"))
+		writeRaw(t, conn, true, opText, []byte("two"))
+		_, _, err := conn.ReadMessage()
+		wantClose(t, err, CloseProtocolError)
+		wantClose(t, <-ended, CloseProtocolError)
+	})
+}
+
+func TestPingPong(t *testing.T) {
+	server, _ := echoServer(t, Config{})
+	conn := dial(t, server.URL, nil, Config{})
+
+	if err := conn.Ping([]byte("are you there")); err != nil {
+		t.Fatalf("Ping: %v", err)
+	}
+	// Read the raw frame, ReadMessage would consume the pong
+	fin, op, payload, err := conn.readFrame(0)
+	if err != nil {
+		t.Fatalf("reading pong: %v", err)
+	}
+	if !fin || op != opPong || string(payload) != "are you there" {
+		t.Errorf("reply = fin %t, opcode %#x, %q; want a pong echoing the ping", fin, op, payload)
+	}
+
+	if err := conn.Ping(make([]byte, 126)); err == nil {
+		t.Error("Ping with 126 bytes succeeded, want an error")
+	}
+}
+
+func TestKeepAliveAnswersPings(t *testing.T) {
+	pinged := make(chan struct{})
+	server := httptest.NewServer(Handler(func(conn *Conn) {
+		// The client's ReadMessage answers the server's pings while
+		// waiting for this message
+		time.Sleep(150 * time.Millisecond)
+		conn.WriteText("still here")
+		close(pinged)
+		conn.ReadMessage()
+	}, Config{PingInterval: 20 * time.Millisecond}))
+	defer server.Close()
+
+	conn := dial(t, server.URL, nil, Config{PingInterval: -1})
+	_, data, err := conn.ReadMessage()
+	if err != nil {
+		t.Fatalf("ReadMessage: %v", err)
+	}
+	if string(data) != "still here" {
+		t.Errorf("message = %q, want %q", data, "still here")
+	}
+	<-pinged
+}
+
+func TestClose(t *testing.T) {
+	t.Run("by the client", func(t *testing.T) {
+		server, ended := echoServer(t, Config{})
+		conn := dial(t, server.URL, nil, Config{})
+		if err := conn.CloseWithReason(CloseGoingAway, "bye"); err != nil {
+			t.Fatalf("CloseWithReason: %v", err)
+		}
+		closeErr := wantClose(t, <-ended, CloseGoingAway)
+		if closeErr.Reason != "bye" {
+			t.Errorf("reason = %q, want bye", closeErr.Reason)
+		}
+		if err := conn.WriteText("after close"); err != ErrClosed {
+			t.Errorf("write after close = %v, want ErrClosed", err)
+		}
+		select {
+		case <-conn.Done():
+		case <-time.After(time.Second):
+			t.Error("connection still open after Close")
+		}
+	})
+
+	t.Run("by the server", func(t *testing.T) {
+		server := httptest.NewServer(Handler(func(conn *Conn) {
+			conn.CloseWithReason(ClosePolicyViolation, "go away")
+		}))
+		defer server.Close()
+
+		conn := dial(t, server.URL, nil, Config{})
+		_, _, err := conn.ReadMessage()
+		closeErr := wantClose(t, err, ClosePolicyViolation)
+		if closeErr.Reason != "go away" {
+			t.Errorf("reason = %q, want %q", closeErr.Reason, "go away")
+		}
+		// The error sticks
+		if _, _, again := conn.ReadMessage(); again != err {
+			t.Errorf("second read = %v, want %v", again, err)
+		}
+	})
+
+	t.Run("invalid close code", func(t *testing.T) {
+		server, ended := echoServer(t, Config{})
+		conn := dial(t, server.URL, nil, Config{})
+		writeRaw(t, conn, true, opClose, binary.BigEndian.AppendUint16(nil, 1005))
+		_, _, err := conn.ReadMessage()
+		wantClose(t, err, CloseProtocolError)
+		<-ended
+	})
+}
+
+func TestReadLimit(t *testing.T) {
+	tests := []struct {
+		name   string
+		limit  int64
+		length int
+	}{
+		{"configured", 64, 65},
+		{"default when zero", 0, DefaultReadLimit + 1},
+		{"default when negative", -1, DefaultReadLimit + 1},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			server, ended := echoServer(t, Config{ReadLimit: tt.limit})
+			conn := dial(t, server.URL, nil, Config{})
+
+			if err := conn.WriteMessage(BinaryMessage, make([]byte, tt.length-1)); err != nil {
+				t.Fatalf("WriteMessage: %v", err)
+			}
+			if _, _, err := conn.ReadMessage(); err != nil {
+				t.Fatalf("message at the limit: %v", err)
+			}
+
+			conn.WriteMessage(BinaryMessage, make([]byte, tt.length))
+			_, _, err := conn.ReadMessage()
+			wantClose(t, err, CloseMessageTooBig)
+			wantClose(t, <-ended, CloseMessageTooBig)
+		})
+	}
+
+	t.Run("fragments count together", func(t *testing.T) {
+		server, ended := echoServer(t, Config{ReadLimit: 10})
+		conn := dial(t, server.URL, nil, Config{})
+		writeRaw(t, conn, false, opText, []byte("123456"))
+		writeRaw(t, conn, true, opContinuation, []byte("789012"))
+		_, _, err := conn.ReadMessage()
+		wantClose(t, err, CloseMessageTooBig)
+		<-ended
+	})
+
+	t.Run("announced length is not allocated", func(t *testing.T) {
+		server, ended := echoServer(t, Config{ReadLimit: -1})
+		conn := dial(t, server.URL, nil, Config{})
+		// Only the header of a 1 TiB frame; the server must refuse it
+		// before reading, let alone allocating, the payload
+		header := binary.BigEndian.AppendUint64([]byte{0x80 | opBinary, 0x80 | 127}, 1<<40)
+		if _, err := conn.conn.Write(append(header, 1, 2, 3, 4)); err != nil {
+			t.Fatal(err)
+		}
+		closeErr := wantClose(t, <-ended, CloseMessageTooBig)
+		if !strings.Contains(closeErr.Reason, "read limit") {
+			t.Errorf("reason = %q, want it to mention the read limit", closeErr.Reason)
+		}
+	})
+}